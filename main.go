@@ -1,66 +1,684 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
+	"runtime/debug"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/cxpsemea/Cx1ClientGo"
+	"github.com/cxpsemea/cx1e2e/pkg/mockcx1"
 	"github.com/cxpsemea/cx1e2e/pkg/process"
 	"github.com/sirupsen/logrus"
 	easy "github.com/t-tomalak/logrus-easy-formatter"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
+// set at build time through -ldflags by goreleaser
+var (
+	version = "dev"
+	commit  = "none"
+	date    = "unknown"
+)
+
+type command struct {
+	Name        string
+	Description string
+	Run         func(args []string) uint
+}
+
+var commands = []command{
+	{"run", "Run the tests in a configuration (default if no command is given)", runCommand},
+	{"validate", "Validate and lint a configuration without connecting to Cx1", validateCommand},
+	{"plan", "List the tests in a configuration in the order they will be executed", planCommand},
+	{"cleanup", "Delete the objects created by the tests in a configuration, eg: after an aborted run", cleanupCommand},
+	{"report", "Re-render a JSON report in other formats", reportCommand},
+	{"history", "Show the results stored in the history directory, with an HTML trend page", historyCommand},
+	{"diff", "Compare the results of two JSON reports: regressions, fixes, added/removed tests and duration changes", diffCommand},
+	{"serve-mock", "Serve an in-memory imitation of the Cx1 and IAM APIs, eg: to run the examples in CI without a tenant", serveMockCommand},
+	{"version", "Print version information", versionCommand},
+}
+
 func main() {
-	os.Exit(int(run())) // returns the number of tests that failed
+	os.Exit(int(dispatch(os.Args[1:]))) // see process.ExitPolicy for the exit codes of the run command
 }
 
-func run() uint {
-	logger := logrus.New()
-	logger.SetLevel(logrus.InfoLevel)
-	myformatter := &easy.Formatter{}
-	myformatter.TimestampFormat = "2006-01-02 15:04:05.000"
-	myformatter.LogFormat = "[%lvl%][%time%] %msg%\n"
-	logger.SetFormatter(myformatter)
-	logger.SetOutput(os.Stdout)
+func dispatch(args []string) uint {
+	// a bare invocation (cx1e2e --config ...) runs the tests, as in earlier versions
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runCommand(args)
+	}
+
+	for _, c := range commands {
+		if c.Name == args[0] {
+			return c.Run(args[1:])
+		}
+	}
+
+	if args[0] == "help" {
+		printUsage()
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command: %v\n\n", args[0])
+	printUsage()
+	return process.EXIT_CONFIG_ERROR
+}
+
+// the exit code of a command whose flags could not be parsed, -h is not an error
+func parseExitCode(err error) uint {
+	if errors.Is(err, flag.ErrHelp) {
+		return process.EXIT_OK
+	}
+	return process.EXIT_CONFIG_ERROR
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: cx1e2e [command] [flags]\n\nCommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10v %v\n", c.Name, c.Description)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'cx1e2e <command> -h' for the flags of a command.\n")
+}
+
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: cx1e2e %v\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func runCommand(args []string) uint {
+	logger := newLogger("")
+
+	fs := newFlagSet("run", "[run] --config tests.yaml [flags]")
+	testConfig := fs.String("config", "", "Path to a test config.yaml")
+	connection := addConnectionFlags(fs)
+	logs := addLogFlags(fs)
+	ReportType := fs.String("report-type", "html,json", fmt.Sprintf("Report output formats, comma-separated: %v", strings.Join(process.ReportTypes, ", ")))
+	ReportName := fs.String("report-name", "cx1e2e_result", "Report output base name")
+	Engines := fs.String("engines", process.DEFAULT_ENGINES, "Run tests only for these engines")
+	Threads := fs.Int("threads", 1, "How many concurrent tests to run")
+	InlineReport := fs.Bool("inline-report", false, "Print the report (json/html) contents at the end of execution")
+	LegacySummary := fs.Bool("legacy-summary", false, "Optional: Write the summary of the JSON report with the fixed Area structure of earlier versions instead of Modules")
+	StrictLint := fs.Bool("strict-lint", false, "Optional: Do not run the tests if the configuration linter reports any issues")
+	HAR := fs.String("har", "", "Optional: Save the HTTP traffic of failing tests (failures) or of all tests (all) as HAR files next to the report")
+	MetricsFile := fs.String("metrics-file", "", "Optional: Write Prometheus metrics to this file at the end of the run, eg: for the node_exporter textfile collector")
+	MetricsListen := fs.String("metrics-listen", "", "Optional: Serve Prometheus metrics on this address while the tests run, eg: :9090")
+	TraceFile := fs.String("trace-file", "", "Optional: Write OpenTelemetry traces of the run as OTLP/JSON lines to this file, or - for stdout")
+	Events := fs.String("events", "", "Optional: Write machine-readable events (test started, finished, ...) as NDJSON to this file as they happen, or - for stdout")
+	TraceEndpoint := fs.String("trace-endpoint", "", "Optional: Send OpenTelemetry traces of the run to this OTLP/HTTP endpoint, eg: http://localhost:4318/v1/traces")
+	HistoryDir := fs.String("history-dir", "", "Optional: Directory in which the results of every run are stored, for the history command")
+	ArtifactsDir := fs.String("artifacts-dir", "", "Optional: Directory in which the files downloaded by the tests (reports, scan logs, import logs, query sources) are kept, linked from the report")
+	LogDir := fs.String("log-dir", "", "Optional: Directory in which the log of each thread and of each failed test is written, linked from the report")
+	Environments := fs.String("env", "", "Optional: Comma-separated list of environments (defined in the test config.yaml) to run the tests against, eg: dev,stage,prod")
+	EnvParallel := fs.Bool("env-parallel", false, "Optional: Run the tests against all environments in parallel instead of in sequence")
+	ExitMode := fs.String("exit-code", process.EXIT_MODE_COUNT, fmt.Sprintf("Exit code on test failures: %v (number of failed tests, up to %d), %v (1 if any test failed) or %v (1 if more than --max-failures-allowed tests failed)", process.EXIT_MODE_COUNT, process.EXIT_MAX_COUNT, process.EXIT_MODE_BINARY, process.EXIT_MODE_THRESHOLD))
+	FailOnSkip := fs.Bool("fail-on-skip", false, "Optional: Count skipped tests as failures for the exit code")
+	MaxFailuresAllowed := fs.Uint("max-failures-allowed", 0, "Optional: Number of failed tests allowed with --exit-code threshold")
+	Record := fs.String("record", "", "Optional: Record every HTTP interaction with Cx1 to a cassette in this directory")
+	Replay := fs.String("replay", "", "Optional: Replay the HTTP interactions recorded in this cassette directory instead of connecting to Cx1")
+	CassetteIgnore := fs.String("cassette-ignore", "", "Optional: Comma-separated JSON fields and query parameters whose values are ignored when matching requests to the cassette, eg: createdAt,startDate")
+
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	if *Events == "-" && *TraceFile == "-" {
+		logger.Errorf("--events - and --trace-file - can not both write to stdout")
+		return process.EXIT_CONFIG_ERROR
+	}
+	if *Events == "-" { // keep stdout for the events only
+		logger.SetOutput(os.Stderr)
+	}
+	logs.Setup(logger)
+
+	exitPolicy := process.ExitPolicy{
+		Mode:               strings.ToLower(*ExitMode),
+		FailOnSkip:         *FailOnSkip,
+		MaxFailuresAllowed: *MaxFailuresAllowed,
+	}
+	if err := exitPolicy.Validate(); err != nil {
+		logger.Errorf("%s", err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	if err := connection.Resolve(fs, logger); err != nil {
+		logger.Errorf("%s", err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	if *testConfig == "" || (!connection.Options().HasCredentials() && *Environments == "") {
+		logger.Info("The purpose of this tool is to automate testing of the API for various workflows based on the yaml configuration. For help run: cx1e2e.exe -h")
+		logger.Error("Test configuration yaml or authentication (API Key, client+secret, or access token) not provided.")
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	Config, err := loadConfig(logger, *testConfig, *StrictLint)
+	if err != nil {
+		logger.Errorf("%s", err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	if *logs.Level == "" && Config.LogLevel != "" {
+		setLogLevel(logger, Config.LogLevel)
+	}
+
+	if *Threads <= 0 {
+		*Threads = 1
+	}
+
+	if *ReportName != "" {
+		Config.ReportName = *ReportName
+	}
+	if Config.ReportName == "" {
+		Config.ReportName = "cx1e2e_result"
+	}
+
+	if *ReportType != "" {
+		Config.ReportType = strings.ToLower(*ReportType)
+	}
+	if Config.ReportType == "" {
+		Config.ReportType = "html,json"
+	} else if !process.ValidReportType(Config.ReportType) {
+		logger.Errorf("Supplied report type (%v) is invalid, using default", Config.ReportType)
+		Config.ReportType = "html,json"
+	}
+
+	if *HistoryDir != "" {
+		Config.HistoryDir = *HistoryDir
+	}
+
+	if *ArtifactsDir != "" {
+		if err := os.MkdirAll(*ArtifactsDir, 0755); err != nil {
+			logger.Errorf("Failed to create artifacts directory %v: %s", *ArtifactsDir, err)
+			return process.EXIT_CONFIG_ERROR
+		}
+		Config.ArtifactsDir = *ArtifactsDir
+	}
+	Config.LogDir = *LogDir
+
+	switch strings.ToLower(*HAR) {
+	case process.HAR_NONE, process.HAR_FAILURES, process.HAR_ALL:
+		Config.HAR = strings.ToLower(*HAR)
+	default:
+		logger.Errorf("Supplied HAR option (%v) is invalid, options are: failures, all", *HAR)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	connection.Apply(&Config)
+	Config.InlineReport = *InlineReport
+	if *Events == "-" {
+		Config.Console = os.Stderr
+	}
+	Config.LegacySummary = *LegacySummary
+	Config.Telemetry = process.NewAPITelemetry()
+	Config.Telemetry.CaptureHAR = Config.HAR != process.HAR_NONE
+
+	if *MetricsFile != "" || *MetricsListen != "" {
+		Config.Metrics = process.NewMetrics()
+		Config.MetricsFile = *MetricsFile
+	}
+	if *MetricsListen != "" {
+		server := Config.Metrics.Serve(*MetricsListen, logger)
+		defer server.Close()
+	}
+	if *TraceFile != "" || *TraceEndpoint != "" {
+		exporters := []sdktrace.SpanExporter{}
+		if *TraceFile != "" {
+			exporter, err := process.NewFileSpanExporter(*TraceFile)
+			if err != nil {
+				logger.Errorf("Failed to open trace file %v: %s", *TraceFile, err)
+				return process.EXIT_CONFIG_ERROR
+			}
+			exporters = append(exporters, exporter)
+		}
+		if *TraceEndpoint != "" {
+			exporter, err := process.NewHTTPSpanExporter(*TraceEndpoint)
+			if err != nil {
+				logger.Errorf("Failed to create trace exporter for %v: %s", *TraceEndpoint, err)
+				return process.EXIT_CONFIG_ERROR
+			}
+			exporters = append(exporters, exporter)
+		}
+		Config.Tracer = process.NewTracer(logger, version, exporters...)
+		defer Config.Tracer.Shutdown()
+	}
+	if *Events != "" {
+		if Config.Events, err = process.NewEventWriter(*Events); err != nil {
+			logger.Errorf("Failed to create events file %v: %s", *Events, err)
+			return process.EXIT_CONFIG_ERROR
+		}
+		defer Config.Events.Close()
+	}
+	if *Record != "" || *Replay != "" {
+		mode, dir := process.CASSETTE_RECORD, *Record
+		if *Replay != "" {
+			if *Record != "" {
+				logger.Error("Only one of --record and --replay can be used")
+				return process.EXIT_CONFIG_ERROR
+			}
+			mode, dir = process.CASSETTE_REPLAY, *Replay
+		}
+		ignore := []string{}
+		for _, field := range strings.Split(*CassetteIgnore, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				ignore = append(ignore, field)
+			}
+		}
+		if Config.Cassette, err = process.NewCassette(mode, dir, ignore); err != nil {
+			logger.Errorf("Failed to open cassette %v: %s", dir, err)
+			return process.EXIT_CONFIG_ERROR
+		}
+		defer Config.Cassette.Close()
+		logger.Infof("Running with cassette %v (%v)", dir, mode)
+	}
+	Config.Engines = process.ParseEngines(*Engines)
+
+	if *Environments != "" {
+		return runEnvironments(logger, &Config, strings.Split(*Environments, ","), *EnvParallel, connection.Options(), *Threads, exitPolicy)
+	}
+
+	cx1client, err := createClient(logger, &Config, connection.Options())
+	if err != nil {
+		logger.Errorf("Failed to create Cx1 client: %s", err)
+		return clientExitCode(err)
+	}
+
+	Config.InitTestIDs()
+
+	report := process.RunTestsReport(cx1client, logger, &Config, *Threads)
+	return exitPolicy.Code(report.Summary.Total)
+}
+
+func validateCommand(args []string) uint {
+	logger := newLogger("")
+
+	fs := newFlagSet("validate", "validate --config tests.yaml [flags]")
+	testConfig := fs.String("config", "", "Path to a test config.yaml")
+	StrictLint := fs.Bool("strict-lint", false, "Optional: Fail if the configuration linter reports any issues")
+	logs := addLogFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	logs.Setup(logger)
+
+	if *testConfig == "" {
+		logger.Error("Test configuration yaml not provided.")
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	Config, err := loadConfig(logger, *testConfig, *StrictLint)
+	if err != nil {
+		logger.Errorf("%s", err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	Config.InitTestIDs()
+	logger.Infof("Test configuration %v is valid: %d tests defined", *testConfig, Config.TestCount)
+	return 0
+}
+
+func planCommand(args []string) uint {
+	logger := newLogger("")
+
+	fs := newFlagSet("plan", "plan --config tests.yaml [flags]")
+	testConfig := fs.String("config", "", "Path to a test config.yaml")
+	logs := addLogFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	logs.Setup(logger)
+
+	if *testConfig == "" {
+		logger.Error("Test configuration yaml not provided.")
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	Config, err := loadConfig(logger, *testConfig, false)
+	if err != nil {
+		logger.Errorf("%s", err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	Config.InitTestIDs()
+	for _, set := range Config.Tests {
+		set.PrintTests()
+	}
+	return 0
+}
+
+func cleanupCommand(args []string) uint {
+	logger := newLogger("")
+
+	fs := newFlagSet("cleanup", "cleanup --config tests.yaml [flags]")
+	testConfig := fs.String("config", "", "Path to the test config.yaml which created the objects")
+	connection := addConnectionFlags(fs)
+	logs := addLogFlags(fs)
+	Engines := fs.String("engines", process.DEFAULT_ENGINES, "Clean up objects only for these engines")
+	DryRun := fs.Bool("dry-run", false, "Optional: Only list the objects which would be deleted")
+	Events := fs.String("events", "", "Optional: Write a cleanup event per object as NDJSON to this file, or - for stdout")
 
-	testConfig := flag.String("config", "", "Path to a test config.yaml")
-	APIKey := flag.String("apikey", "", "CheckmarxOne API Key (if not using client id/secret)")
-	ClientID := flag.String("client", "", "CheckmarxOne Client ID (if not using API Key)")
-	ClientSecret := flag.String("secret", "", "CheckmarxOne Client Secret (if not using API Key)")
-	Cx1URL := flag.String("cx1", "", "Optional: CheckmarxOne platform URL, if not defined in the test config.yaml")
-	IAMURL := flag.String("iam", "", "Optional: CheckmarxOne IAM URL, if not defined in the test config.yaml")
-	AccessToken := flag.String("access-token", "", "CheckmarxOne Access Token (if not using API Key or client id/secret)")
-	Tenant := flag.String("tenant", "", "Optional: CheckmarxOne tenant, if not defined in the test config.yaml")
-	LogLevel := flag.String("log", "", "Log level: TRACE, DEBUG, INFO, WARNING, ERROR, FATAL")
-	ReportType := flag.String("report-type", "html,json", "Report output format: html or json")
-	ReportName := flag.String("report-name", "cx1e2e_result", "Report output base name")
-	Engines := flag.String("engines", "sast,sca,iac,apisec,2ms,containers", "Run tests only for these engines")
-	Proxy := flag.String("proxy", "", "Optional: Proxy to use when connecting to CheckmarxOne")
-	NoTLS := flag.Bool("notls", false, "Optional: Disable TLS verification")
-	Threads := flag.Int("threads", 1, "How many concurrent tests to run")
-	LogFile := flag.String("logfile", "", "Optional: output log to file")
-	InlineReport := flag.Bool("inline-report", false, "Print the report (json/html) contents at the end of execution")
-	UserAgent := flag.String("useragent", "", "Optional: Custom User-Agent string to use in API requests")
-	IPv4 := flag.Bool("ipv4", false, "Optional: Use IPv4 only for API requests")
-	IPv6 := flag.Bool("ipv6", false, "Optional: Use IPv6 only for API requests")
-
-	flag.Parse()
-
-	if *LogFile != "" {
-		file, err := os.OpenFile(*LogFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	if *Events == "-" {
+		logger.SetOutput(os.Stderr)
+	}
+	logs.Setup(logger)
+
+	if err := connection.Resolve(fs, logger); err != nil {
+		logger.Errorf("%s", err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	if *testConfig == "" || !connection.Options().HasCredentials() {
+		logger.Error("Test configuration yaml or authentication (API Key, client+secret, or access token) not provided.")
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	Config, err := loadConfig(logger, *testConfig, false)
+	if err != nil {
+		logger.Errorf("%s", err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	connection.Apply(&Config)
+	Config.Engines = process.ParseEngines(*Engines)
+
+	if *Events != "" {
+		if Config.Events, err = process.NewEventWriter(*Events); err != nil {
+			logger.Errorf("Failed to create events file %v: %s", *Events, err)
+			return process.EXIT_CONFIG_ERROR
+		}
+		defer Config.Events.Close()
+	}
+
+	cx1client, err := createClient(logger, &Config, connection.Options())
+	if err != nil {
+		logger.Errorf("Failed to create Cx1 client: %s", err)
+		return clientExitCode(err)
+	}
+
+	Config.InitTestIDs()
+	result := process.Cleanup(cx1client, logger, &Config, *DryRun)
+	return process.ExitCount(result.Failed)
+}
+
+func reportCommand(args []string) uint {
+	logger := newLogger("")
+
+	fs := newFlagSet("report", "report --input cx1e2e_result.json [flags]")
+	Input := fs.String("input", "", "Path to a JSON report written by a previous run")
+	ReportType := fs.String("report-type", "html", fmt.Sprintf("Report output formats, comma-separated: %v", strings.Join(process.ReportTypes, ", ")))
+	ReportName := fs.String("report-name", "", "Report output base name (default: the input name without extension)")
+	InlineReport := fs.Bool("inline-report", false, "Print the report contents after writing it")
+	LegacySummary := fs.Bool("legacy-summary", false, "Optional: Write the summary of the JSON report with the fixed Area structure of earlier versions instead of Modules")
+
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	if *Input == "" {
+		logger.Error("Input JSON report not provided.")
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	reportType := strings.ToLower(*ReportType)
+	if !process.ValidReportType(reportType) {
+		logger.Errorf("Supplied report type (%v) is invalid", *ReportType)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	reportData, err := process.LoadReportJSON(*Input)
+	if err != nil {
+		logger.Errorf("Failed to load report %v: %s", *Input, err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	reportName := *ReportName
+	if reportName == "" {
+		reportName = strings.TrimSuffix(*Input, ".json")
+	}
+	reportData.Summary.Legacy = *LegacySummary
+
+	var inline io.Writer
+	if *InlineReport {
+		inline = os.Stdout
+	}
+	process.OutputReports(&reportData, reportType, reportName, inline, logger)
+	return 0
+}
+
+func historyCommand(args []string) uint {
+	logger := newLogger("")
+
+	fs := newFlagSet("history", "history --history-dir dir [flags]")
+	HistoryDir := fs.String("history-dir", "", "Directory in which the results were stored with run --history-dir")
+	Environment := fs.String("env", "", "Optional: Only show runs against this environment")
+	Version := fs.String("version", "", "Optional: Only show runs against Cx1 versions containing this string")
+	Limit := fs.Int("limit", 0, "Optional: Only show the last N runs of each environment")
+	ReportName := fs.String("report-name", "cx1e2e_history", "Trend report output base name (empty for console only)")
+
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	if *HistoryDir == "" {
+		logger.Error("History directory not provided.")
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	entries, err := process.LoadHistory(logger, *HistoryDir, process.HistoryFilter{Environment: *Environment, Version: *Version, Limit: *Limit})
+	if err != nil {
+		logger.Errorf("Failed to load history from %v: %s", *HistoryDir, err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	process.OutputHistoryConsole(entries)
+
+	if *ReportName != "" {
+		filename := fmt.Sprintf("%v.html", *ReportName)
+		if err := process.OutputHistoryHTML(filename, entries); err != nil {
+			logger.Errorf("Failed to write HTML history report to %v: %s", filename, err)
+			return 1
+		}
+		logger.Infof("Wrote history trend report to %v", filename)
+	}
+	return 0
+}
+
+func diffCommand(args []string) uint {
+	logger := newLogger("")
+
+	fs := newFlagSet("diff", "diff [flags] base.json current.json")
+	ReportType := fs.String("report-type", "html,json", "Diff output formats in addition to the console, comma-separated: html, json (empty for console only)")
+	ReportName := fs.String("report-name", "cx1e2e_diff", "Diff report output base name")
+	DurationThreshold := fs.Float64("duration-threshold", 50, "Report tests whose duration changed by at least this percentage")
+	DurationMinimum := fs.Float64("duration-min", 5, "Ignore duration changes of fewer seconds than this")
+
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	reportType := strings.ToLower(*ReportType)
+	for _, t := range strings.Split(reportType, ",") {
+		if t != "" && t != "html" && t != "json" {
+			logger.Errorf("Supplied report type (%v) is invalid, options are: html, json", *ReportType)
+			return process.EXIT_CONFIG_ERROR
+		}
+	}
+
+	base, err := process.LoadReportJSON(fs.Arg(0))
+	if err != nil {
+		logger.Errorf("Failed to load report %v: %s", fs.Arg(0), err)
+		return process.EXIT_CONFIG_ERROR
+	}
+	current, err := process.LoadReportJSON(fs.Arg(1))
+	if err != nil {
+		logger.Errorf("Failed to load report %v: %s", fs.Arg(1), err)
+		return process.EXIT_CONFIG_ERROR
+	}
+
+	options := process.DiffOptions{
+		DurationThreshold: *DurationThreshold,
+		DurationMinimum:   *DurationMinimum,
+	}
+	diff := process.DiffReports(fs.Arg(0), &base, fs.Arg(1), &current, options)
+	process.GenerateDiffReport(&diff, reportType, *ReportName, logger)
+
+	// the number of regressions, so that the diff can gate the promotion of a build
+	return process.ExitCount(uint(len(diff.Regressions)))
+}
+
+func serveMockCommand(args []string) uint {
+	logger := newLogger("")
+
+	fs := newFlagSet("serve-mock", "serve-mock [flags]")
+	Listen := fs.String("listen", "127.0.0.1:8080", "Address on which the mock Cx1 and IAM APIs are served")
+	Tenant := fs.String("tenant", "mock", "Tenant (realm) name")
+	ClientID := fs.String("client", "mock-client", "OAuth client ID which is accepted with --secret")
+	ClientSecret := fs.String("secret", "mock-secret", "OAuth client secret")
+	ScanDuration := fs.Duration("scan-duration", 5*time.Second, "Time from a scan being queued to completing")
+	Engines := fs.String("engines", "sast,sca,kics,apisec,containers,microengines", "Licensed engines, comma-separated")
+	Flags := fs.String("flags", "", "Optional: Comma-separated feature flags to enable, eg: CVSS_V3_ENABLED")
+	logs := addLogFlags(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return parseExitCode(err)
+	}
+	logs.Setup(logger)
+
+	options := mockcx1.Options{
+		Tenant:       *Tenant,
+		ClientID:     *ClientID,
+		ClientSecret: *ClientSecret,
+		ScanDuration: *ScanDuration,
+		Flags:        map[string]bool{},
+	}
+	for _, e := range strings.Split(*Engines, ",") {
+		if e = strings.TrimSpace(strings.ToLower(e)); e != "" {
+			options.Engines = append(options.Engines, e)
+		}
+	}
+	for _, f := range strings.Split(*Flags, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			options.Flags[f] = true
+		}
+	}
+
+	host, port, err := net.SplitHostPort(*Listen)
+	if err != nil {
+		logger.Errorf("Invalid listen address %v: %s", *Listen, err)
+		return process.EXIT_CONFIG_ERROR
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "127.0.0.1"
+	}
+	baseURL := fmt.Sprintf("http://%v", net.JoinHostPort(host, port))
+
+	server := mockcx1.New(options)
+	logger.Infof("Serving mock Cx1 tenant %v on %v", options.Tenant, baseURL)
+	logger.Infof("Run the tests with: cx1e2e run --cx1 %v --iam %v --tenant %v --apikey %v --config tests.yaml", baseURL, baseURL, options.Tenant, server.APIKey(baseURL))
+	logger.Infof("or with: --client %v --secret %v", options.ClientID, options.ClientSecret)
+
+	if err := http.ListenAndServe(*Listen, server); err != nil {
+		logger.Errorf("Failed to serve on %v: %s", *Listen, err)
+		return 1
+	}
+	return 0
+}
+
+func versionCommand(args []string) uint {
+	fmt.Printf("cx1e2e %v (commit %v, built %v)\n", version, commit, date)
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "github.com/cxpsemea/Cx1ClientGo" {
+				fmt.Printf("Cx1ClientGo %v\n", dep.Version)
+			}
+		}
+	}
+	return 0
+}
+
+func loadConfig(logger *logrus.Logger, path string, strictLint bool) (process.TestConfig, error) {
+	Config, err := process.LoadConfig(logger, path)
+	if err != nil {
+		return Config, fmt.Errorf("failed to load configuration file %v: %s", path, err)
+	}
+
+	if !Config.IsValid(logger) {
+		return Config, fmt.Errorf("test configuration failed to validate - review the logs and update the YAMLs")
+	}
+
+	if issues := Config.Lint(); len(issues) > 0 {
+		for _, issue := range issues {
+			logger.Warnf("Configuration lint: %v", issue.String())
+		}
+		if strictLint {
+			return Config, fmt.Errorf("test configuration has %d lint issues - review the logs and update the YAMLs, or mark objects as PreExisting", len(issues))
+		}
+	}
+
+	return Config, nil
+}
+
+type logFlags struct {
+	Level  *string
+	File   *string
+	Format *string
+}
+
+func addLogFlags(fs *flag.FlagSet) *logFlags {
+	return &logFlags{
+		Level:  fs.String("log", "", "Log level: TRACE, DEBUG, INFO, WARNING, ERROR, FATAL"),
+		File:   fs.String("logfile", "", "Optional: output log to file"),
+		Format: fs.String("log-format", "text", "Log format: text, or json for one JSON object per line with the thread and test as fields"),
+	}
+}
+
+func (l *logFlags) Setup(logger *logrus.Logger) {
+	switch strings.ToLower(*l.Format) {
+	case "json":
+		logger.SetFormatter(&logrus.JSONFormatter{TimestampFormat: "2006-01-02T15:04:05.000Z07:00"})
+	case "text", "":
+	default:
+		logger.Errorf("Supplied log format (%v) is invalid, using text", *l.Format)
+	}
+
+	if *l.File != "" {
+		file, err := os.OpenFile(*l.File, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0666)
 		if err != nil {
-			logger.Errorf("Failed to create log file '%v': %v", *LogFile, err)
+			logger.Errorf("Failed to create log file '%v': %v", *l.File, err)
 		}
-		mw := io.MultiWriter(os.Stdout, file)
+		mw := io.MultiWriter(logger.Out, file)
 		logger.SetOutput(mw)
-		logger.Infof("Logging to file %v", *LogFile)
+		logger.Infof("Logging to file %v", *l.File)
 	}
 
-	switch strings.ToUpper(*LogLevel) {
+	if !setLogLevel(logger, *l.Level) {
+		logger.Info("Log level set to default: INFO")
+	}
+}
+
+func setLogLevel(logger *logrus.Logger, level string) bool {
+	switch strings.ToUpper(level) {
 	case "TRACE":
 		logger.Info("Setting log level to TRACE")
 		logger.SetLevel(logrus.TraceLevel)
@@ -80,91 +698,155 @@ func run() uint {
 		logger.Info("Setting log level to FATAL")
 		logger.SetLevel(logrus.FatalLevel)
 	default:
-		logger.Info("Log level set to default: INFO")
+		return false
 	}
+	return true
+}
 
-	if *testConfig == "" || (*APIKey == "" && (*ClientID == "" || *ClientSecret == "") && *AccessToken == "") {
-		logger.Info("The purpose of this tool is to automate testing of the API for various workflows based on the yaml configuration. For help run: cx1e2e.exe -h")
-		logger.Error("Test configuration yaml or authentication (API Key, client+secret, or access token) not provided.")
-		return 1
-	}
+// flags used by every command which connects to Cx1
+type connectionFlags struct {
+	Cx1URL       *string
+	IAMURL       *string
+	Tenant       *string
+	APIKey       *string
+	ClientID     *string
+	ClientSecret *string
+	AccessToken  *string
+	Proxy        *string
+	NoTLS        *bool
+	UserAgent    *string
+	IPv4         *bool
+	IPv6         *bool
+	Profile      *string
+	ProfilesFile *string
+}
 
-	var err error
-	Config, err := process.LoadConfig(logger, *testConfig)
-	if err != nil {
-		logger.Errorf("Failed to load configuration file %v: %s", *testConfig, err)
-		return 1
+func addConnectionFlags(fs *flag.FlagSet) *connectionFlags {
+	return &connectionFlags{
+		APIKey:       fs.String("apikey", "", "CheckmarxOne API Key (if not using client id/secret)"),
+		ClientID:     fs.String("client", "", "CheckmarxOne Client ID (if not using API Key)"),
+		ClientSecret: fs.String("secret", "", "CheckmarxOne Client Secret (if not using API Key)"),
+		Cx1URL:       fs.String("cx1", "", "Optional: CheckmarxOne platform URL, if not defined in the test config.yaml"),
+		IAMURL:       fs.String("iam", "", "Optional: CheckmarxOne IAM URL, if not defined in the test config.yaml"),
+		AccessToken:  fs.String("access-token", "", "CheckmarxOne Access Token (if not using API Key or client id/secret)"),
+		Tenant:       fs.String("tenant", "", "Optional: CheckmarxOne tenant, if not defined in the test config.yaml"),
+		Proxy:        fs.String("proxy", "", "Optional: Proxy to use when connecting to CheckmarxOne"),
+		NoTLS:        fs.Bool("notls", false, "Optional: Disable TLS verification"),
+		UserAgent:    fs.String("useragent", "", "Optional: Custom User-Agent string to use in API requests"),
+		IPv4:         fs.Bool("ipv4", false, "Optional: Use IPv4 only for API requests"),
+		IPv6:         fs.Bool("ipv6", false, "Optional: Use IPv6 only for API requests"),
+		Profile:      fs.String("profile", "", "Optional: Name of a connection profile (URLs, tenant, credentials, proxy) to use, command-line flags override the profile values"),
+		ProfilesFile: fs.String("profiles-file", "", fmt.Sprintf("Optional: Path to the connection profiles file (default %v)", process.DefaultProfilesPath())),
 	}
+}
 
-	if !Config.IsValid(logger) {
-		logger.Errorf("Test configuration failed to validate - review the logs and update the YAMLs")
-		return 1
+// fills in the values which were not passed on the command-line from the selected connection profile
+func (c *connectionFlags) Resolve(fs *flag.FlagSet, logger *logrus.Logger) error {
+	if *c.Profile == "" {
+		return nil
 	}
 
-	if *LogLevel == "" && Config.LogLevel != "" {
-		switch strings.ToUpper(*LogLevel) {
-		case "TRACE":
-			logger.Info("Setting log level to TRACE")
-			logger.SetLevel(logrus.TraceLevel)
-		case "DEBUG":
-			logger.Info("Setting log level to DEBUG")
-			logger.SetLevel(logrus.DebugLevel)
-		case "INFO":
-			logger.Info("Setting log level to INFO")
-			logger.SetLevel(logrus.InfoLevel)
-		case "WARNING":
-			logger.Info("Setting log level to WARNING")
-			logger.SetLevel(logrus.WarnLevel)
-		case "ERROR":
-			logger.Info("Setting log level to ERROR")
-			logger.SetLevel(logrus.ErrorLevel)
-		case "FATAL":
-			logger.Info("Setting log level to FATAL")
-			logger.SetLevel(logrus.FatalLevel)
-		}
-		*LogLevel = Config.LogLevel
+	profile, err := process.LoadProfile(logger, *c.ProfilesFile, *c.Profile)
+	if err != nil {
+		return fmt.Errorf("failed to load connection profile %v: %s", *c.Profile, err)
 	}
+	logger.Infof("Using connection profile %v", profile.Name)
 
-	if *Threads <= 0 {
-		*Threads = 1
+	// values passed explicitly on the command-line take precedence over the profile
+	explicit := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+	useProfile := func(name string, value *string, profileValue string) {
+		if !explicit[name] && profileValue != "" {
+			*value = profileValue
+		}
 	}
 
-	if *ReportName != "" {
-		Config.ReportName = *ReportName
-	}
-	if Config.ReportName == "" {
-		Config.ReportName = "cx1e2e_result"
+	useProfile("cx1", c.Cx1URL, profile.Cx1URL)
+	useProfile("iam", c.IAMURL, profile.IAMURL)
+	useProfile("tenant", c.Tenant, profile.Tenant)
+	useProfile("proxy", c.Proxy, profile.ProxyURL)
+	useProfile("useragent", c.UserAgent, profile.UserAgent)
+	if !explicit["notls"] && profile.NoTLS {
+		*c.NoTLS = true
 	}
 
-	if *ReportType != "" {
-		Config.ReportType = strings.ToLower(*ReportType)
-	}
-	if Config.ReportType == "" {
-		Config.ReportType = "html,json"
-	} else {
-		if Config.ReportType != "html" && Config.ReportType != "json" && Config.ReportType != "html,json" {
-			logger.Errorf("Supplied report type (%v) is invalid, using default", Config.ReportType)
-			Config.ReportType = "html,json"
+	// credentials are overridden field by field (eg: --secret with the ClientID of the profile),
+	// unless the command-line selects a different type of authentication than the profile
+	switch profile.GetAuthType() {
+	case "apikey":
+		if !explicit["client"] && !explicit["secret"] && !explicit["access-token"] {
+			useProfile("apikey", c.APIKey, profile.APIKey)
+		}
+	case "oauth":
+		if !explicit["apikey"] && !explicit["access-token"] {
+			useProfile("client", c.ClientID, profile.ClientID)
+			useProfile("secret", c.ClientSecret, profile.ClientSecret)
+		}
+	case "token":
+		if !explicit["apikey"] && !explicit["client"] && !explicit["secret"] {
+			useProfile("access-token", c.AccessToken, profile.AccessToken)
 		}
 	}
+	return nil
+}
 
-	Config.IPv4 = *IPv4
-	Config.IPv6 = *IPv6
+func (c *connectionFlags) Options() ConnectionOptions {
+	return ConnectionOptions{
+		Cx1URL:       *c.Cx1URL,
+		IAMURL:       *c.IAMURL,
+		Tenant:       *c.Tenant,
+		APIKey:       *c.APIKey,
+		ClientID:     *c.ClientID,
+		ClientSecret: *c.ClientSecret,
+		AccessToken:  *c.AccessToken,
+		UserAgent:    *c.UserAgent,
+	}
+}
 
-	var cx1client *Cx1ClientGo.Cx1Client
+// applies the HTTP transport settings to the configuration
+func (c *connectionFlags) Apply(Config *process.TestConfig) {
+	Config.IPv4 = *c.IPv4
+	Config.IPv6 = *c.IPv6
 
-	if *Proxy != "" {
-		Config.ProxyURL = *Proxy
+	if *c.Proxy != "" {
+		Config.ProxyURL = *c.Proxy
 	}
 
-	if *NoTLS {
+	if *c.NoTLS {
 		Config.NoTLS = true
 	}
+}
 
+type ConnectionOptions struct {
+	Cx1URL       string
+	IAMURL       string
+	Tenant       string
+	APIKey       string
+	ClientID     string
+	ClientSecret string
+	AccessToken  string
+	UserAgent    string
+}
+
+func (c ConnectionOptions) HasCredentials() bool {
+	return c.APIKey != "" || (c.ClientID != "" && c.ClientSecret != "") || c.AccessToken != ""
+}
+
+// the HTTP client could not be created from the configuration (eg: the cassette), as opposed to failing to connect to Cx1
+var errHTTPClient = errors.New("failed to create HTTP client")
+
+// the exit code of a command whose Cx1 client could not be created
+func clientExitCode(err error) uint {
+	if errors.Is(err, errHTTPClient) {
+		return process.EXIT_CONFIG_ERROR
+	}
+	return process.EXIT_AUTH_ERROR
+}
+
+func createClient(logger *logrus.Logger, Config *process.TestConfig, connection ConnectionOptions) (*Cx1ClientGo.Cx1Client, error) {
 	httpClient, err := Config.CreateHTTPClient(logger)
 	if err != nil {
-		logger.Errorf("Failed to create HTTP client: %s", err)
-		return 1
+		return nil, fmt.Errorf("%w: %s", errHTTPClient, err)
 	}
 
 	cx1config := Cx1ClientGo.Cx1ClientConfiguration{
@@ -173,45 +855,50 @@ func run() uint {
 		HTTPHeaders: http.Header{},
 	}
 
-	if *Tenant != "" {
-		Config.Tenant = *Tenant
-		cx1config.Tenant = *Tenant
+	if connection.Tenant != "" {
+		Config.Tenant = connection.Tenant
+		cx1config.Tenant = connection.Tenant
 	}
-	if *Cx1URL != "" {
-		Config.Cx1URL = *Cx1URL
-		cx1config.Cx1Url = *Cx1URL
+	if connection.Cx1URL != "" {
+		Config.Cx1URL = connection.Cx1URL
+		cx1config.Cx1Url = connection.Cx1URL
 	}
-	if *IAMURL != "" {
-		Config.IAMURL = *IAMURL
-		cx1config.IAMUrl = *IAMURL
+	if connection.IAMURL != "" {
+		Config.IAMURL = connection.IAMURL
+		cx1config.IAMUrl = connection.IAMURL
 	}
-	if *UserAgent != "" {
-		cx1config.HTTPHeaders.Set("User-Agent", *UserAgent)
-		logger.Infof("Using custom User-Agent: %s", *UserAgent)
+	if connection.UserAgent != "" {
+		cx1config.HTTPHeaders.Set("User-Agent", connection.UserAgent)
+		logger.Infof("Using custom User-Agent: %s", connection.UserAgent)
 	} else {
 		cx1config.HTTPHeaders.Set("User-Agent", "Cx1e2e")
 	}
 
-	if *AccessToken != "" {
-		cx1config.Auth.AccessToken = *AccessToken
-		Config.AuthType = fmt.Sprintf("Access Token %v", Cx1ClientGo.ShortenGUID(*AccessToken))
-	} else if *APIKey != "" {
-		cx1config.Auth.APIKey = *APIKey
-		Config.AuthType = fmt.Sprintf("APIKey %v", Cx1ClientGo.ShortenGUID(*APIKey))
+	if connection.AccessToken != "" {
+		cx1config.Auth.AccessToken = connection.AccessToken
+		Config.AuthType = fmt.Sprintf("Access Token %v", Cx1ClientGo.ShortenGUID(connection.AccessToken))
+	} else if connection.APIKey != "" {
+		cx1config.Auth.APIKey = connection.APIKey
+		Config.AuthType = fmt.Sprintf("APIKey %v", Cx1ClientGo.ShortenGUID(connection.APIKey))
 	} else {
-		cx1config.Auth.ClientID = *ClientID
-		cx1config.Auth.ClientSecret = *ClientSecret
-		Config.AuthType = fmt.Sprintf("OAuth client %v", *ClientID)
+		cx1config.Auth.ClientID = connection.ClientID
+		cx1config.Auth.ClientSecret = connection.ClientSecret
+		Config.AuthType = fmt.Sprintf("OAuth client %v", connection.ClientID)
 	}
 
-	cx1client, err = Cx1ClientGo.NewClientWithOptions(cx1config)
-
+	cx1client, err := Cx1ClientGo.NewClientWithOptions(cx1config)
 	if err != nil {
-		logger.Errorf("Failed to create Cx1 client: %s", err)
-		return 1
+		return nil, err
 	}
 
 	logger.Infof("Created Cx1 client: %s", cx1client.String())
+	// each thread gets a client with the same credentials, so that its API calls are attributed to its tests
+	Config.NewClient = func(httpClient *http.Client, logger *logrus.Logger) (*Cx1ClientGo.Cx1Client, error) {
+		threadConfig := cx1config
+		threadConfig.HttpClient = httpClient
+		threadConfig.Logger = logger
+		return Cx1ClientGo.NewClientWithOptions(threadConfig)
+	}
 
 	cx1client.SetDeprecationWarning(false)
 	if cx1client.IsUser() {
@@ -235,27 +922,141 @@ func run() uint {
 		logger.Errorf("Failed to get version info: %s", err)
 	}
 	logger.Infof("Cx1 version: %v", Config.EnvironmentVersion.String())
-	Config.InlineReport = *InlineReport
 
-	EngineList := strings.Split(strings.ToLower(*Engines), ",")
-	for _, e := range EngineList {
-		switch e {
-		case "sast":
-			Config.Engines.SAST = true
-		case "sca":
-			Config.Engines.SCA = true
-		case "kics", "iac":
-			Config.Engines.IAC = true
-		case "apisec":
-			Config.Engines.APISEC = true
-		case "2ms", "secrets":
-			Config.Engines.Secrets = true
-		case "containers":
-			Config.Engines.Containers = true
+	return cx1client, nil
+}
+
+// runs the same test suite against each of the named environments and generates a comparison report
+func runEnvironments(logger *logrus.Logger, Config *process.TestConfig, names []string, parallel bool, defaults ConnectionOptions, threads int, exitPolicy process.ExitPolicy) uint {
+	configs := []process.TestConfig{}
+	connections := []ConnectionOptions{}
+
+	for _, name := range names {
+		env, err := Config.GetEnvironment(strings.TrimSpace(name))
+		if err != nil {
+			logger.Errorf("Failed to find environment: %s", err)
+			return process.EXIT_CONFIG_ERROR
+		}
+
+		envConfig, err := Config.ForEnvironment(logger, env)
+		if err != nil {
+			logger.Errorf("Failed to load configuration for environment %v: %s", env.Name, err)
+			return process.EXIT_CONFIG_ERROR
 		}
+
+		connection := defaults
+		// an environment which only supplies credentials keeps the URLs and tenant of the command-line or profile
+		if env.Cx1URL != "" {
+			connection.Cx1URL = env.Cx1URL
+		}
+		if env.IAMURL != "" {
+			connection.IAMURL = env.IAMURL
+		}
+		if env.Tenant != "" {
+			connection.Tenant = env.Tenant
+		}
+		if env.HasCredentials() {
+			connection.APIKey = env.APIKey()
+			connection.ClientID = env.ClientID()
+			connection.ClientSecret = env.ClientSecret()
+			connection.AccessToken = env.AccessToken()
+		}
+
+		if !connection.HasCredentials() {
+			logger.Errorf("No authentication (API Key, client+secret, or access token) available for environment %v - check the referenced environment variables", env.Name)
+			return process.EXIT_CONFIG_ERROR
+		}
+
+		configs = append(configs, envConfig)
+		connections = append(connections, connection)
 	}
 
-	Config.InitTestIDs()
+	reports := make([]process.EnvironmentReport, len(configs))
+	errorCodes := make([]uint, len(configs))
+	runEnvironment := func(id int) {
+		envLogger := newLogger(configs[id].Environment)
+		envLogger.SetLevel(logger.GetLevel())
+		envLogger.SetOutput(logger.Out)
+		if _, ok := logger.Formatter.(*logrus.JSONFormatter); ok {
+			envLogger.SetFormatter(logger.Formatter)
+			envLogger.AddHook(fieldHook{"environment": configs[id].Environment})
+		}
+
+		reports[id].Name = configs[id].Environment
+		cx1client, err := createClient(envLogger, &configs[id], connections[id])
+		if err != nil {
+			envLogger.Errorf("Failed to create Cx1 client: %s", err)
+			reports[id].Error = err.Error()
+			errorCodes[id] = clientExitCode(err)
+			return
+		}
+
+		reports[id].Report = process.RunTestsReport(cx1client, envLogger, &configs[id], threads)
+	}
+
+	if parallel {
+		var wg sync.WaitGroup
+		for id := range configs {
+			wg.Add(1)
+			go func(id int) {
+				defer wg.Done()
+				runEnvironment(id)
+			}(id)
+		}
+		wg.Wait()
+	} else {
+		for id := range configs {
+			runEnvironment(id)
+		}
+	}
+
+	process.GenerateComparisonReport(reports, logger, Config)
 
-	return process.RunTests(cx1client, logger, &Config, *Threads)
+	var total process.Counter
+	for _, r := range reports {
+		total.Pass += r.Report.Summary.Total.Pass
+		total.Fail += r.Report.Summary.Total.Fail
+		total.Skip += r.Report.Summary.Total.Skip
+	}
+	// failed tests in the other environments take precedence over an environment in which the tests could not be run
+	if code := exitPolicy.Code(total); code != process.EXIT_OK {
+		return code
+	}
+	for _, code := range errorCodes {
+		if code != process.EXIT_OK {
+			return code
+		}
+	}
+	return process.EXIT_OK
+}
+
+// adds fields to every entry, eg: the environment in the JSON log format
+type fieldHook logrus.Fields
+
+func (h fieldHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h fieldHook) Fire(entry *logrus.Entry) error {
+	for k, v := range h {
+		if _, ok := entry.Data[k]; !ok {
+			entry.Data[k] = v
+		}
+	}
+	return nil
+}
+
+func newLogger(prefix string) *logrus.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.InfoLevel)
+	myformatter := &easy.Formatter{}
+	myformatter.TimestampFormat = "2006-01-02 15:04:05.000"
+	if prefix != "" {
+		myformatter.LogFormat = fmt.Sprintf("[%%lvl%%][%%time%%][%v] %%msg%%\n", prefix)
+	} else {
+		myformatter.LogFormat = "[%lvl%][%time%] %msg%\n"
+	}
+	logger.SetFormatter(myformatter)
+	logger.SetOutput(os.Stdout)
+	return logger
 }
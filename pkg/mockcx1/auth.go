@@ -0,0 +1,184 @@
+package mockcx1
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const tokenLifetime = time.Hour
+
+func (s *Server) authRoutes() {
+	s.mux.HandleFunc("POST /auth/realms/{realm}/protocol/openid-connect/token", s.token)
+	s.mux.HandleFunc("GET /auth/realms/{realm}/protocol/openid-connect/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		claims, _ := s.parseToken(bearer(r))
+		user, ok := s.users.get(str(claims, "sub"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "user not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, object{"sub": user["id"], "preferred_username": user["username"], "email": user["email"], "given_name": user["firstName"], "family_name": user["lastName"]})
+	})
+}
+
+// APIKey returns a key for the admin user, for a server reached at baseURL (eg: http://127.0.0.1:8080)
+func (s *Server) APIKey(baseURL string) string {
+	baseURL = strings.TrimRight(baseURL, "/")
+	return s.sign(object{
+		"typ": "Offline",
+		"sub": s.adminID,
+		"azp": "ast-app",
+		"iss": fmt.Sprintf("%v/auth/realms/%v", baseURL, s.options.Tenant),
+		"aud": fmt.Sprintf("%v/auth/realms/%v", baseURL, s.options.Tenant),
+		"iat": time.Now().Unix(),
+	})
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("realm") != s.options.Tenant {
+		writeJSON(w, http.StatusNotFound, object{"error": "Realm does not exist"})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, object{"error": "invalid_request"})
+		return
+	}
+
+	var subject, clientID string
+	switch r.PostForm.Get("grant_type") {
+	case "refresh_token":
+		claims, err := s.parseToken(r.PostForm.Get("refresh_token"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, object{"error": "invalid_grant", "error_description": err.Error()})
+			return
+		}
+		subject, clientID = str(claims, "sub"), str(claims, "azp")
+	case "client_credentials":
+		clientID = r.PostForm.Get("client_id")
+		client, ok := s.clients.find("clientId", clientID)
+		if !ok || str(client, "secret") != r.PostForm.Get("client_secret") {
+			writeJSON(w, http.StatusUnauthorized, object{"error": "unauthorized_client", "error_description": "Invalid client or Invalid client credentials"})
+			return
+		}
+		subject = str(client, "serviceAccountUserId")
+	default:
+		writeJSON(w, http.StatusBadRequest, object{"error": "unsupported_grant_type"})
+		return
+	}
+
+	user, ok := s.users.get(subject)
+	if !ok {
+		writeJSON(w, http.StatusBadRequest, object{"error": "invalid_grant", "error_description": "User not found"})
+		return
+	}
+
+	baseURL := requestBaseURL(r)
+	claims := object{
+		"typ":                "Bearer",
+		"sub":                subject,
+		"azp":                clientID,
+		"iss":                fmt.Sprintf("%v/auth/realms/%v", baseURL, s.options.Tenant),
+		"iat":                time.Now().Unix(),
+		"exp":                time.Now().Add(tokenLifetime).Unix(),
+		"name":               fmt.Sprintf("%v %v", str(user, "firstName"), str(user, "lastName")),
+		"preferred_username": user["username"],
+		"email":              user["email"],
+		"ast-base-url":       baseURL,
+		"tenant_id":          s.tenantID(),
+		"tenant_name":        s.options.Tenant,
+		"ast-license": object{
+			"ID":          1,
+			"TenantID":    s.tenantID(),
+			"IsActive":    true,
+			"PackageID":   1,
+			"LicenseData": object{"allowedEngines": s.allowedEngines(), "features": []string{}},
+			"PackageName": "Mock",
+		},
+	}
+	if strings.HasPrefix(str(user, "username"), "service-account-") {
+		claims["clientId"] = clientID
+	}
+
+	writeJSON(w, http.StatusOK, object{
+		"access_token":  s.sign(claims),
+		"expires_in":    int(tokenLifetime.Seconds()),
+		"refresh_token": r.PostForm.Get("refresh_token"),
+		"token_type":    "Bearer",
+		"scope":         "openid profile email",
+	})
+}
+
+// the engine names as they appear in a Cx1 license
+func (s *Server) allowedEngines() []string {
+	names := map[string]string{"sast": "SAST", "sca": "SCA", "kics": "KICS", "iac": "KICS", "apisec": "API Security", "containers": "Containers", "microengines": "Enterprise Secrets", "2ms": "Enterprise Secrets"}
+	engines := []string{}
+	for _, e := range s.options.Engines {
+		if name, ok := names[strings.ToLower(e)]; ok {
+			engines = append(engines, name)
+		}
+	}
+	return engines
+}
+
+func (s *Server) tenantID() string {
+	return str(s.astApp, "tenantId")
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	claims, err := s.parseToken(bearer(r))
+	return err == nil && str(claims, "typ") == "Bearer"
+}
+
+func bearer(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// the URL the client used to reach the server, which is where it expects the APIs
+func requestBaseURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	return fmt.Sprintf("%v://%v", scheme, r.Host)
+}
+
+func (s *Server) sign(claims object) string {
+	header, _ := json.Marshal(object{"alg": "HS256", "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(s.signature(unsigned))
+}
+
+func (s *Server) signature(unsigned string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(unsigned))
+	return mac.Sum(nil)
+}
+
+func (s *Server) parseToken(token string) (object, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed token")
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, s.signature(parts[0]+"."+parts[1])) {
+		return nil, fmt.Errorf("invalid token signature")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token: %s", err)
+	}
+	claims := object{}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed token: %s", err)
+	}
+	if exp, ok := claims["exp"].(float64); ok && time.Now().Unix() > int64(exp) {
+		return nil, fmt.Errorf("token expired")
+	}
+	return claims, nil
+}
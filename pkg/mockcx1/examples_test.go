@@ -0,0 +1,105 @@
+package mockcx1_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cxpsemea/Cx1ClientGo"
+	"github.com/cxpsemea/cx1e2e/pkg/mockcx1"
+	"github.com/cxpsemea/cx1e2e/pkg/process"
+	"github.com/sirupsen/logrus"
+)
+
+// example suites which use APIs the mock does not implement, or which fail on purpose
+var unsupportedSuites = map[string]string{
+	"access":        "access assignments are not implemented",
+	"analytics":     "analytics are not implemented",
+	"iacquery":      "queries and audit sessions are not implemented",
+	"import":        "imports are not implemented",
+	"query_old_api": "queries and audit sessions are not implemented",
+	"sastquery":     "queries and audit sessions are not implemented",
+	"failure":       "the suite demonstrates failing tests",
+}
+
+// runs the all.yaml of each example suite against the mock, as in CI without a tenant
+func TestExamples(t *testing.T) {
+	suites, err := filepath.Glob("../../examples/*/all.yaml")
+	if err != nil || len(suites) == 0 {
+		t.Fatalf("no example suites found: %v", err)
+	}
+	t.Setenv("E2E_RUN_SUFFIX", "_mock")
+
+	for _, suite := range suites {
+		dir := filepath.Dir(suite)
+		name := filepath.Base(dir)
+		t.Run(name, func(t *testing.T) {
+			if reason, ok := unsupportedSuites[name]; ok {
+				t.Skip(reason)
+			}
+			t.Parallel()
+
+			server := httptest.NewServer(mockcx1.New(mockcx1.Options{ScanDuration: 500 * time.Millisecond}))
+			defer server.Close()
+
+			data, err := os.ReadFile(suite)
+			if err != nil {
+				t.Fatal(err)
+			}
+			root, err := filepath.Abs(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			logger := logrus.New()
+			logger.SetOutput(testWriter{t})
+			runner, err := process.NewRunnerWithOptions(process.RunnerOptions{
+				ConfigYAML: data,
+				ConfigDir:  root,
+				Logger:     logger,
+				Client: func(httpClient *http.Client, logger *logrus.Logger) (*Cx1ClientGo.Cx1Client, error) {
+					config := Cx1ClientGo.Cx1ClientConfiguration{
+						HttpClient: httpClient,
+						Logger:     logger,
+						Cx1Url:     server.URL,
+						IAMUrl:     server.URL,
+						Tenant:     "mock",
+					}
+					config.Auth.ClientID = "mock-client"
+					config.Auth.ClientSecret = "mock-secret"
+					return Cx1ClientGo.NewClientWithOptions(config)
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := runner.Run(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Summary.Pass == 0 {
+				t.Errorf("no tests passed")
+			}
+			for _, r := range result.Results {
+				if r.Result == process.TST_FAIL {
+					t.Errorf("%v %v %v failed: %v", r.Name, r.CRUD, r.Module, r.Reason)
+				}
+			}
+		})
+	}
+}
+
+// writes the log of a run to the test output, shown when the test fails or with -v
+type testWriter struct {
+	t *testing.T
+}
+
+func (w testWriter) Write(p []byte) (int, error) {
+	w.t.Log(string(p))
+	return len(p), nil
+}
@@ -0,0 +1,708 @@
+package mockcx1
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const iamPrefix = "/auth/admin/realms/{realm}"
+
+// the roles of the ast-app client in a new tenant, the composite roles with the roles they include
+var defaultAppRoles = map[string][]string{
+	"ast-admin":                {"manage-projects", "manage-applications", "manage-groups", "view-scans", "create-scan", "manage-reports", "view-results", "update-result", "manage-queries", "manage-presets", "manage-access"},
+	"ast-scanner":              {"view-projects", "create-project", "view-scans", "create-scan", "view-results"},
+	"ast-viewer":               {"view-projects", "view-scans", "view-results"},
+	"manage-projects":          nil,
+	"manage-applications":      nil,
+	"manage-groups":            nil,
+	"manage-reports":           nil,
+	"manage-queries":           nil,
+	"manage-presets":           nil,
+	"manage-access":            nil,
+	"create-project":           nil,
+	"create-scan":              nil,
+	"view-projects":            nil,
+	"view-scans":               nil,
+	"view-results":             nil,
+	"update-result":            nil,
+	"view-scans-if-in-group":   nil,
+	"view-results-if-in-group": nil,
+}
+
+var defaultRealmRoles = []string{"default-roles", "offline_access", "uma_authorization", "iam-admin"}
+
+// creates the objects which exist in a new tenant
+func (s *Server) seed() {
+	s.astApp = s.clients.add(object{"clientId": "ast-app", "name": "ast-app", "enabled": true, "tenantId": newID()})
+
+	for _, name := range defaultRealmRoles {
+		s.roles.add(object{"name": name, "clientRole": false, "containerId": s.tenantID(), "composite": false})
+	}
+	for _, name := range sortedKeys(defaultAppRoles) {
+		s.roles.add(object{"name": name, "clientRole": true, "containerId": s.astApp["id"], "composite": false})
+	}
+	for name, composites := range defaultAppRoles {
+		role, _ := s.clientRole(str(s.astApp, "id"), name)
+		ids := []any{}
+		for _, c := range composites {
+			composite, _ := s.clientRole(str(s.astApp, "id"), c)
+			ids = append(ids, composite["id"])
+		}
+		role["composite"] = len(ids) > 0
+		role["composites"] = ids
+	}
+
+	admin := s.users.add(object{"username": "mock-admin", "email": "admin@mock.local", "firstName": "Mock", "lastName": "Admin", "enabled": true, "createdTimestamp": 0})
+	s.adminID = str(admin, "id")
+	s.grantAdmin(admin)
+
+	client := s.newClient(object{"clientId": s.options.ClientID, "name": s.options.ClientID, "enabled": true, "serviceAccountsEnabled": true})
+	client["secret"] = s.options.ClientSecret
+	serviceAccount, _ := s.users.get(str(client, "serviceAccountUserId"))
+	s.grantAdmin(serviceAccount)
+
+	for _, name := range []string{"ASA Premium", "ASA SAST Default", "Checkmarx Default", "OWASP TOP 10 - 2021"} {
+		s.presets.add(object{"id": fmt.Sprintf("%d", len(s.presets.order)+1), "name": name, "description": "Built-in preset", "custom": false, "associatedProjects": 0})
+	}
+}
+
+func (s *Server) grantAdmin(user object) {
+	admin, _ := s.clientRole(str(s.astApp, "id"), "ast-admin")
+	iamAdmin, _ := s.roles.find("name", "iam-admin")
+	user["roleIds"] = []any{admin["id"], iamAdmin["id"]}
+}
+
+func (s *Server) iamRoutes() {
+	s.mux.HandleFunc("GET "+iamPrefix, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, object{"id": s.tenantID(), "realm": s.options.Tenant, "displayName": s.options.Tenant, "enabled": true})
+	})
+
+	// groups
+	s.mux.HandleFunc("GET "+iamPrefix+"/groups", func(w http.ResponseWriter, r *http.Request) {
+		search := strings.ToLower(r.URL.Query().Get("search"))
+		groups := []object{}
+		for _, g := range s.groups.list(func(o object) bool { return str(o, "parentId") == "" }) {
+			if search == "" || s.groupMatches(g, search) {
+				groups = append(groups, s.groupView(g))
+			}
+		}
+		writeJSON(w, http.StatusOK, page(r, groups))
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/groups/count", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, object{"count": len(s.groups.list(func(o object) bool { return str(o, "parentId") == "" }))})
+	})
+	s.mux.HandleFunc("POST "+iamPrefix+"/groups", func(w http.ResponseWriter, r *http.Request) {
+		s.createGroup(w, r, "")
+	})
+	s.mux.HandleFunc("POST "+iamPrefix+"/groups/{id}/children", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.groups.get(r.PathValue("id")); !ok {
+			writeError(w, http.StatusNotFound, "Could not find group by id")
+			return
+		}
+		s.createGroup(w, r, r.PathValue("id"))
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/groups/{id}/children", func(w http.ResponseWriter, r *http.Request) {
+		children := []object{}
+		for _, c := range s.groups.list(func(o object) bool { return str(o, "parentId") == r.PathValue("id") }) {
+			children = append(children, s.groupView(c))
+		}
+		writeJSON(w, http.StatusOK, page(r, children))
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/groups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if g, ok := s.groups.get(r.PathValue("id")); ok {
+			writeJSON(w, http.StatusOK, s.groupView(g))
+		} else {
+			writeError(w, http.StatusNotFound, "Could not find group by id")
+		}
+	})
+	s.mux.HandleFunc("PUT "+iamPrefix+"/groups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		g, ok := s.groups.get(r.PathValue("id"))
+		update, valid := readObject(w, r)
+		if !valid {
+			return
+		} else if !ok {
+			writeError(w, http.StatusNotFound, "Could not find group by id")
+			return
+		}
+		delete(update, "subGroups")
+		delete(update, "path")
+		merge(g, update)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("DELETE "+iamPrefix+"/groups/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !s.deleteGroup(r.PathValue("id")) {
+			writeError(w, http.StatusNotFound, "Could not find group by id")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/group-by-path/{path...}", func(w http.ResponseWriter, r *http.Request) {
+		for _, g := range s.groups.list(nil) {
+			if s.groupPath(g) == "/"+strings.Trim(r.PathValue("path"), "/") {
+				writeJSON(w, http.StatusOK, s.groupView(g))
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Group path does not exist")
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/groups/{id}/role-mappings", func(w http.ResponseWriter, r *http.Request) {
+		g, ok := s.groups.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "Could not find group by id")
+			return
+		}
+		writeJSON(w, http.StatusOK, s.roleMappings(g))
+	})
+	s.roleMappingRoutes("groups", s.groups)
+
+	// users
+	s.mux.HandleFunc("GET "+iamPrefix+"/users", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		exact := query.Get("exact") == "true"
+		users := s.users.list(func(o object) bool {
+			for _, field := range []string{"username", "email", "firstName", "lastName"} {
+				if value := query.Get(field); value != "" && !(str(o, field) == value || (!exact && strings.Contains(strings.ToLower(str(o, field)), strings.ToLower(value)))) {
+					return false
+				}
+			}
+			search := strings.ToLower(query.Get("search"))
+			return search == "" || strings.Contains(strings.ToLower(str(o, "username")+" "+str(o, "email")), search)
+		})
+		views := []object{}
+		for _, u := range users {
+			views = append(views, userView(u))
+		}
+		writeJSON(w, http.StatusOK, page(r, views))
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/users/count", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, len(s.users.order))
+	})
+	s.mux.HandleFunc("POST "+iamPrefix+"/users", func(w http.ResponseWriter, r *http.Request) {
+		user, ok := readObject(w, r)
+		if !ok {
+			return
+		}
+		user["username"] = strings.ToLower(str(user, "username"))
+		if _, exists := s.users.find("username", str(user, "username")); exists {
+			writeJSON(w, http.StatusConflict, object{"errorMessage": "User exists with same username"})
+			return
+		}
+		delete(user, "id")
+		user["createdTimestamp"] = 0
+		s.users.add(user)
+		if groups := strList(user, "groups"); len(groups) > 0 {
+			ids := []any{}
+			for _, path := range groups {
+				for _, g := range s.groups.list(nil) {
+					if s.groupPath(g) == path || str(g, "name") == path {
+						ids = append(ids, g["id"])
+					}
+				}
+			}
+			user["groupIds"] = ids
+		}
+		delete(user, "groups")
+		s.created(w, r, user, true)
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if u, ok := s.users.get(r.PathValue("id")); ok {
+			writeJSON(w, http.StatusOK, userView(u))
+		} else {
+			writeError(w, http.StatusNotFound, "User not found")
+		}
+	})
+	s.mux.HandleFunc("PUT "+iamPrefix+"/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		u, ok := s.users.get(r.PathValue("id"))
+		update, valid := readObject(w, r)
+		if !valid {
+			return
+		} else if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		delete(update, "username")
+		delete(update, "groups")
+		merge(u, update)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("DELETE "+iamPrefix+"/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !s.users.remove(r.PathValue("id")) {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/users/{id}/groups", func(w http.ResponseWriter, r *http.Request) {
+		u, ok := s.users.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		groups := []object{}
+		for _, id := range strList(u, "groupIds") {
+			if g, ok := s.groups.get(id); ok {
+				groups = append(groups, object{"id": g["id"], "name": g["name"], "path": s.groupPath(g)})
+			}
+		}
+		writeJSON(w, http.StatusOK, groups)
+	})
+	s.mux.HandleFunc("PUT "+iamPrefix+"/users/{id}/groups/{group}", func(w http.ResponseWriter, r *http.Request) {
+		u, ok := s.users.get(r.PathValue("id"))
+		_, groupOk := s.groups.get(r.PathValue("group"))
+		if !ok || !groupOk {
+			writeError(w, http.StatusNotFound, "User or group not found")
+			return
+		}
+		if ids := strList(u, "groupIds"); !slices.Contains(ids, r.PathValue("group")) {
+			u["groupIds"] = toAny(append(ids, r.PathValue("group")))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("DELETE "+iamPrefix+"/users/{id}/groups/{group}", func(w http.ResponseWriter, r *http.Request) {
+		u, ok := s.users.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		u["groupIds"] = toAny(slices.DeleteFunc(strList(u, "groupIds"), func(id string) bool { return id == r.PathValue("group") }))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/users/{id}/role-mappings", func(w http.ResponseWriter, r *http.Request) {
+		u, ok := s.users.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, s.roleMappings(u))
+	})
+	s.roleMappingRoutes("users", s.users)
+
+	// roles
+	s.mux.HandleFunc("GET "+iamPrefix+"/roles", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, page(r, s.roles.list(func(o object) bool { return o["clientRole"] == false })))
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/roles/{name}", func(w http.ResponseWriter, r *http.Request) {
+		for _, role := range s.roles.list(func(o object) bool { return o["clientRole"] == false }) {
+			if str(role, "name") == r.PathValue("name") {
+				writeJSON(w, http.StatusOK, role)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, "Could not find role")
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/clients/{id}/roles", func(w http.ResponseWriter, r *http.Request) {
+		search := strings.ToLower(r.URL.Query().Get("search"))
+		writeJSON(w, http.StatusOK, page(r, s.roles.list(func(o object) bool {
+			return str(o, "containerId") == r.PathValue("id") && strings.Contains(strings.ToLower(str(o, "name")), search)
+		})))
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/clients/{id}/roles/{name}", func(w http.ResponseWriter, r *http.Request) {
+		if role, ok := s.clientRole(r.PathValue("id"), r.PathValue("name")); ok {
+			writeJSON(w, http.StatusOK, role)
+		} else {
+			writeError(w, http.StatusNotFound, "Could not find role")
+		}
+	})
+	s.mux.HandleFunc("POST "+iamPrefix+"/clients/{id}/roles", func(w http.ResponseWriter, r *http.Request) {
+		role, ok := readObject(w, r)
+		if !ok {
+			return
+		}
+		if _, exists := s.clientRole(r.PathValue("id"), str(role, "name")); exists {
+			writeJSON(w, http.StatusConflict, object{"errorMessage": fmt.Sprintf("Role with name %v already exists", str(role, "name"))})
+			return
+		}
+		delete(role, "id")
+		role["clientRole"] = true
+		role["containerId"] = r.PathValue("id")
+		role["composite"] = false
+		role["composites"] = []any{}
+		s.roles.add(role)
+		w.Header().Set("Location", fmt.Sprintf("%v%v/%v", requestBaseURL(r), r.URL.Path, str(role, "name")))
+		w.WriteHeader(http.StatusCreated)
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/roles-by-id/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if role, ok := s.roles.get(r.PathValue("id")); ok {
+			writeJSON(w, http.StatusOK, role)
+		} else {
+			writeError(w, http.StatusNotFound, "Could not find role")
+		}
+	})
+	s.mux.HandleFunc("PUT "+iamPrefix+"/roles-by-id/{id}", func(w http.ResponseWriter, r *http.Request) {
+		role, ok := s.roles.get(r.PathValue("id"))
+		update, valid := readObject(w, r)
+		if !valid {
+			return
+		} else if !ok {
+			writeError(w, http.StatusNotFound, "Could not find role")
+			return
+		}
+		for _, field := range []string{"containerId", "clientRole", "composite", "composites"} {
+			delete(update, field)
+		}
+		merge(role, update)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("DELETE "+iamPrefix+"/roles-by-id/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !s.roles.remove(r.PathValue("id")) {
+			writeError(w, http.StatusNotFound, "Could not find role")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/roles-by-id/{id}/composites", func(w http.ResponseWriter, r *http.Request) {
+		role, ok := s.roles.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "Could not find role")
+			return
+		}
+		writeJSON(w, http.StatusOK, s.rolesByID(strList(role, "composites")))
+	})
+	s.mux.HandleFunc("POST "+iamPrefix+"/roles-by-id/{id}/composites", func(w http.ResponseWriter, r *http.Request) {
+		s.updateComposites(w, r, true)
+	})
+	s.mux.HandleFunc("DELETE "+iamPrefix+"/roles-by-id/{id}/composites", func(w http.ResponseWriter, r *http.Request) {
+		s.updateComposites(w, r, false)
+	})
+
+	// clients
+	s.mux.HandleFunc("GET "+iamPrefix+"/clients", func(w http.ResponseWriter, r *http.Request) {
+		clientID := r.URL.Query().Get("clientId")
+		clients := []object{}
+		for _, c := range s.clients.list(func(o object) bool { return clientID == "" || str(o, "clientId") == clientID }) {
+			clients = append(clients, clientView(c))
+		}
+		writeJSON(w, http.StatusOK, page(r, clients))
+	})
+	s.mux.HandleFunc("POST "+iamPrefix+"/clients", func(w http.ResponseWriter, r *http.Request) {
+		client, ok := readObject(w, r)
+		if !ok {
+			return
+		}
+		if _, exists := s.clients.find("clientId", str(client, "clientId")); exists {
+			writeJSON(w, http.StatusConflict, object{"errorMessage": fmt.Sprintf("Client %v already exists", str(client, "clientId"))})
+			return
+		}
+		delete(client, "id")
+		s.newClient(client)
+		s.created(w, r, client, true)
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/clients/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if c, ok := s.clients.get(r.PathValue("id")); ok {
+			writeJSON(w, http.StatusOK, clientView(c))
+		} else {
+			writeError(w, http.StatusNotFound, "Could not find client")
+		}
+	})
+	s.mux.HandleFunc("PUT "+iamPrefix+"/clients/{id}", func(w http.ResponseWriter, r *http.Request) {
+		c, ok := s.clients.get(r.PathValue("id"))
+		update, valid := readObject(w, r)
+		if !valid {
+			return
+		} else if !ok {
+			writeError(w, http.StatusNotFound, "Could not find client")
+			return
+		}
+		delete(update, "secret")
+		delete(update, "serviceAccountUserId")
+		merge(c, update)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("DELETE "+iamPrefix+"/clients/{id}", func(w http.ResponseWriter, r *http.Request) {
+		c, ok := s.clients.get(r.PathValue("id"))
+		if !ok || str(c, "clientId") == "ast-app" {
+			writeError(w, http.StatusNotFound, "Could not find client")
+			return
+		}
+		s.users.remove(str(c, "serviceAccountUserId"))
+		s.clients.remove(str(c, "id"))
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/clients/{id}/service-account-user", func(w http.ResponseWriter, r *http.Request) {
+		c, _ := s.clients.get(r.PathValue("id"))
+		if u, ok := s.users.get(str(c, "serviceAccountUserId")); ok {
+			writeJSON(w, http.StatusOK, userView(u))
+		} else {
+			writeError(w, http.StatusNotFound, "Service account not enabled for the client")
+		}
+	})
+	s.mux.HandleFunc("GET "+iamPrefix+"/clients/{id}/client-secret", func(w http.ResponseWriter, r *http.Request) {
+		if c, ok := s.clients.get(r.PathValue("id")); ok {
+			writeJSON(w, http.StatusOK, object{"type": "secret", "value": c["secret"]})
+		} else {
+			writeError(w, http.StatusNotFound, "Could not find client")
+		}
+	})
+	s.mux.HandleFunc("POST "+iamPrefix+"/clients/{id}/client-secret", func(w http.ResponseWriter, r *http.Request) {
+		if c, ok := s.clients.get(r.PathValue("id")); ok {
+			c["secret"] = newID()
+			writeJSON(w, http.StatusOK, object{"type": "secret", "value": c["secret"]})
+		} else {
+			writeError(w, http.StatusNotFound, "Could not find client")
+		}
+	})
+}
+
+// responds to a create request, Keycloak returns the location of the new object while Cx1 returns the object itself
+func (s *Server) created(w http.ResponseWriter, r *http.Request, o object, keycloak bool) {
+	if keycloak {
+		w.Header().Set("Location", fmt.Sprintf("%v%v/%v", requestBaseURL(r), r.URL.Path, str(o, "id")))
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	writeJSON(w, http.StatusCreated, o)
+}
+
+func (s *Server) createGroup(w http.ResponseWriter, r *http.Request, parentID string) {
+	group, ok := readObject(w, r)
+	if !ok {
+		return
+	}
+	for _, g := range s.groups.list(func(o object) bool { return str(o, "parentId") == parentID }) {
+		if str(g, "name") == str(group, "name") {
+			writeJSON(w, http.StatusConflict, object{"error": fmt.Sprintf("Top level group named '%v' already exists.", str(group, "name"))})
+			return
+		}
+	}
+	delete(group, "id")
+	delete(group, "subGroups")
+	group["parentId"] = parentID
+	if _, ok := group["clientRoles"].(map[string]any); !ok {
+		group["clientRoles"] = map[string]any{}
+	}
+	s.groups.add(group)
+	w.Header().Set("Location", fmt.Sprintf("%v/auth/admin/realms/%v/groups/%v", requestBaseURL(r), s.options.Tenant, str(group, "id")))
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (s *Server) deleteGroup(id string) bool {
+	for _, child := range s.groups.list(func(o object) bool { return str(o, "parentId") == id }) {
+		s.deleteGroup(str(child, "id"))
+	}
+	return s.groups.remove(id)
+}
+
+func (s *Server) groupPath(g object) string {
+	if parent, ok := s.groups.get(str(g, "parentId")); ok {
+		return s.groupPath(parent) + "/" + str(g, "name")
+	}
+	return "/" + str(g, "name")
+}
+
+func (s *Server) groupMatches(g object, search string) bool {
+	if strings.Contains(strings.ToLower(str(g, "name")), search) {
+		return true
+	}
+	for _, child := range s.groups.list(func(o object) bool { return str(o, "parentId") == str(g, "id") }) {
+		if s.groupMatches(child, search) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) groupView(g object) object {
+	view := object{}
+	for k, v := range g {
+		if k != "parentId" && k != "roleIds" {
+			view[k] = v
+		}
+	}
+	view["path"] = s.groupPath(g)
+	subGroups := []object{}
+	for _, child := range s.groups.list(func(o object) bool { return str(o, "parentId") == str(g, "id") }) {
+		subGroups = append(subGroups, s.groupView(child))
+	}
+	view["subGroups"] = subGroups
+	view["subGroupCount"] = len(subGroups)
+	if parent := str(g, "parentId"); parent != "" {
+		view["parentId"] = parent
+	}
+	return view
+}
+
+func userView(u object) object {
+	view := object{}
+	for k, v := range u {
+		if k != "roleIds" && k != "groupIds" {
+			view[k] = v
+		}
+	}
+	return view
+}
+
+func clientView(c object) object {
+	view := object{}
+	for k, v := range c {
+		if k != "secret" && k != "serviceAccountUserId" && k != "tenantId" {
+			view[k] = v
+		}
+	}
+	return view
+}
+
+// a client, with the service account user when enabled
+func (s *Server) newClient(client object) object {
+	if str(client, "secret") == "" {
+		client["secret"] = newID()
+	}
+	s.clients.add(client)
+	if client["serviceAccountsEnabled"] == true {
+		user := s.users.add(object{"username": "service-account-" + strings.ToLower(str(client, "clientId")), "enabled": true, "createdTimestamp": 0, "serviceAccountClientId": client["id"]})
+		client["serviceAccountUserId"] = user["id"]
+	}
+	return client
+}
+
+func (s *Server) clientRole(clientID, name string) (object, bool) {
+	for _, role := range s.roles.list(nil) {
+		if str(role, "containerId") == clientID && str(role, "name") == name {
+			return role, true
+		}
+	}
+	return nil, false
+}
+
+func (s *Server) rolesByID(ids []string) []object {
+	roles := []object{}
+	for _, id := range ids {
+		if role, ok := s.roles.get(id); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+func (s *Server) updateComposites(w http.ResponseWriter, r *http.Request, add bool) {
+	role, ok := s.roles.get(r.PathValue("id"))
+	var changes []object
+	if err := readJSON(r, &changes); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	} else if !ok {
+		writeError(w, http.StatusNotFound, "Could not find role")
+		return
+	}
+	ids := changeRoleIDs(strList(role, "composites"), changes, add)
+	role["composites"] = toAny(ids)
+	role["composite"] = len(ids) > 0
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// the role-mappings of users and groups: realm roles, and the roles of a client
+func (s *Server) roleMappingRoutes(kind string, objects *collection) {
+	prefix := iamPrefix + "/" + kind + "/{id}/role-mappings"
+	mapping := func(realm bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			o, ok := objects.get(r.PathValue("id"))
+			if !ok {
+				writeError(w, http.StatusNotFound, "Could not find "+strings.TrimSuffix(kind, "s"))
+				return
+			}
+			roles := []object{}
+			for _, role := range s.rolesByID(s.roleIDs(o)) {
+				if (realm && role["clientRole"] == false) || (!realm && str(role, "containerId") == r.PathValue("client")) {
+					roles = append(roles, role)
+				}
+			}
+
+			if r.Method == http.MethodGet {
+				writeJSON(w, http.StatusOK, roles)
+				return
+			}
+			var changes []object
+			if err := readJSON(r, &changes); err != nil {
+				writeError(w, http.StatusBadRequest, err.Error())
+				return
+			}
+			s.setRoleIDs(o, changeRoleIDs(s.roleIDs(o), changes, r.Method == http.MethodPost))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+	for _, method := range []string{"GET", "POST", "DELETE"} {
+		s.mux.HandleFunc(method+" "+prefix+"/realm", mapping(true))
+		s.mux.HandleFunc(method+" "+prefix+"/clients/{client}", mapping(false))
+	}
+}
+
+// groups also hold their client roles by name in clientRoles, as sent by the client when creating the group
+func (s *Server) roleIDs(o object) []string {
+	ids := strList(o, "roleIds")
+	if clientRoles, ok := o["clientRoles"].(map[string]any); ok {
+		for clientID, names := range clientRoles {
+			client, _ := s.clients.find("clientId", clientID)
+			for _, name := range toStrings(names) {
+				if role, ok := s.clientRole(str(client, "id"), name); ok && !slices.Contains(ids, str(role, "id")) {
+					ids = append(ids, str(role, "id"))
+				}
+			}
+		}
+	}
+	return ids
+}
+
+func (s *Server) setRoleIDs(o object, ids []string) {
+	o["roleIds"] = toAny(ids)
+	if _, ok := o["clientRoles"]; ok {
+		clientRoles := map[string]any{}
+		for _, role := range s.rolesByID(ids) {
+			if client, ok := s.clients.get(str(role, "containerId")); ok {
+				clientRoles[str(client, "clientId")] = append(toAny(toStrings(clientRoles[str(client, "clientId")])), role["name"])
+			}
+		}
+		o["clientRoles"] = clientRoles
+	}
+}
+
+func (s *Server) roleMappings(o object) object {
+	realm := []object{}
+	clients := object{}
+	for _, role := range s.rolesByID(s.roleIDs(o)) {
+		if role["clientRole"] == false {
+			realm = append(realm, role)
+			continue
+		}
+		client, _ := s.clients.get(str(role, "containerId"))
+		mapping, ok := clients[str(client, "clientId")].(object)
+		if !ok {
+			mapping = object{"id": client["id"], "client": client["clientId"], "mappings": []object{}}
+			clients[str(client, "clientId")] = mapping
+		}
+		mapping["mappings"] = append(mapping["mappings"].([]object), role)
+	}
+	return object{"realmMappings": realm, "clientMappings": clients}
+}
+
+func changeRoleIDs(ids []string, changes []object, add bool) []string {
+	for _, change := range changes {
+		id := str(change, "id")
+		if add && !slices.Contains(ids, id) {
+			ids = append(ids, id)
+		} else if !add {
+			ids = slices.DeleteFunc(ids, func(i string) bool { return i == id })
+		}
+	}
+	return ids
+}
+
+func toAny(list []string) []any {
+	values := make([]any, len(list))
+	for i, v := range list {
+		values[i] = v
+	}
+	return values
+}
+
+func toStrings(v any) []string {
+	list := []string{}
+	if values, ok := v.([]any); ok {
+		for _, value := range values {
+			if s, ok := value.(string); ok {
+				list = append(list, s)
+			}
+		}
+	}
+	return list
+}
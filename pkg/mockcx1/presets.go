@@ -0,0 +1,89 @@
+package mockcx1
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+func (s *Server) presetRoutes() {
+	s.mux.HandleFunc("GET /api/presets", s.listPresets("sast", false))
+	s.mux.HandleFunc("GET /api/queries/presets", s.listPresets("sast", false))
+
+	// the preset manager keeps the SAST presets at /presets and the presets of other engines at /<engine>/presets
+	prefixes := map[string]string{"/api/preset-manager": "sast", "/api/preset-manager/sast": "sast", "/api/preset-manager/kics": "kics", "/api/preset-manager/iac": "kics"}
+	for prefix, engine := range prefixes {
+		s.mux.HandleFunc("GET "+prefix+"/presets", s.listPresets(engine, true))
+		s.mux.HandleFunc("POST "+prefix+"/presets", func(w http.ResponseWriter, r *http.Request) {
+			preset, ok := readObject(w, r)
+			if !ok {
+				return
+			}
+			for _, p := range s.presets.list(nil) {
+				if str(p, "name") == str(preset, "name") && presetMatches(p, engine) {
+					writeError(w, http.StatusConflict, fmt.Sprintf("preset %v already exists", str(preset, "name")))
+					return
+				}
+			}
+			delete(preset, "id")
+			preset["engine"] = engine
+			preset["custom"] = true
+			s.presets.add(preset)
+			writeJSON(w, http.StatusCreated, object{"id": preset["id"]})
+		})
+		s.mux.HandleFunc("GET "+prefix+"/presets/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if p, ok := s.presets.get(r.PathValue("id")); ok && presetMatches(p, engine) {
+				writeJSON(w, http.StatusOK, p)
+			} else {
+				writeError(w, http.StatusNotFound, "preset not found")
+			}
+		})
+		s.mux.HandleFunc("PUT "+prefix+"/presets/{id}", func(w http.ResponseWriter, r *http.Request) {
+			p, ok := s.presets.get(r.PathValue("id"))
+			update, valid := readObject(w, r)
+			if !valid {
+				return
+			} else if !ok || !presetMatches(p, engine) || p["custom"] != true {
+				writeError(w, http.StatusNotFound, "custom preset not found")
+				return
+			}
+			delete(update, "engine")
+			merge(p, update)
+			w.WriteHeader(http.StatusNoContent)
+		})
+		s.mux.HandleFunc("DELETE "+prefix+"/presets/{id}", func(w http.ResponseWriter, r *http.Request) {
+			if p, ok := s.presets.get(r.PathValue("id")); !ok || !presetMatches(p, engine) || p["custom"] != true {
+				writeError(w, http.StatusNotFound, "custom preset not found")
+				return
+			}
+			s.presets.remove(r.PathValue("id"))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+func (s *Server) listPresets(engine string, wrapped bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := strings.ToLower(r.URL.Query().Get("name"))
+		exact := r.URL.Query().Get("exact_match") == "true"
+		presets := s.presets.list(func(o object) bool {
+			if !presetMatches(o, engine) {
+				return false
+			}
+			if exact {
+				return strings.ToLower(str(o, "name")) == name
+			}
+			return strings.Contains(strings.ToLower(str(o, "name")), name)
+		})
+		if !wrapped {
+			writeJSON(w, http.StatusOK, page(r, presets))
+			return
+		}
+		writeJSON(w, http.StatusOK, object{"totalCount": len(presets), "presets": page(r, presets)})
+	}
+}
+
+// the built-in presets are available for every engine
+func presetMatches(preset object, engine string) bool {
+	return str(preset, "engine") == "" || str(preset, "engine") == engine
+}
@@ -0,0 +1,260 @@
+package mockcx1
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// the project configuration of a new project, as key and value
+var defaultProjectConfig = [][2]string{
+	{"scan.config.sast.presetName", "ASA Premium"},
+	{"scan.config.sast.incremental", "false"},
+	{"scan.config.sast.fastScanMode", "false"},
+	{"scan.config.sast.filter", ""},
+	{"scan.config.kics.presetName", "ASA Premium"},
+	{"scan.handler.git.repository", ""},
+	{"scan.handler.git.branch", ""},
+}
+
+func (s *Server) projectRoutes() {
+	s.mux.HandleFunc("GET /api/projects", func(w http.ResponseWriter, r *http.Request) {
+		projects := s.projects.list(matchName(r, "name"))
+		writeJSON(w, http.StatusOK, object{"totalCount": len(s.projects.order), "filteredTotalCount": len(projects), "projects": s.projectViews(page(r, projects))})
+	})
+	s.mux.HandleFunc("POST /api/projects", func(w http.ResponseWriter, r *http.Request) {
+		s.createProject(w, r, "")
+	})
+	s.mux.HandleFunc("POST /api/projects/application/{application}", func(w http.ResponseWriter, r *http.Request) {
+		s.createProject(w, r, r.PathValue("application"))
+	})
+	s.mux.HandleFunc("GET /api/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := s.projects.get(r.PathValue("id")); ok {
+			writeJSON(w, http.StatusOK, s.projectView(p))
+		} else {
+			writeError(w, http.StatusNotFound, "project not found")
+		}
+	})
+	s.mux.HandleFunc("PUT /api/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		p, ok := s.projects.get(r.PathValue("id"))
+		update, valid := readObject(w, r)
+		if !valid {
+			return
+		} else if !ok {
+			writeError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		delete(update, "applicationIds")
+		delete(update, "createdAt")
+		merge(p, update)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("DELETE /api/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id := r.PathValue("id")
+		if !s.projects.remove(id) {
+			writeError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		delete(s.configs, id)
+		for _, scan := range s.scans.list(func(o object) bool { return str(o, "projectId") == id }) {
+			s.deleteScan(str(scan, "id"))
+		}
+		for _, app := range s.applications.list(nil) {
+			app["projectIds"] = toAny(slices.DeleteFunc(strList(app, "projectIds"), func(p string) bool { return p == id }))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("GET /api/projects/branches", func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		branches := []string{}
+		for _, scan := range s.scans.list(func(o object) bool { return str(o, "projectId") == r.URL.Query().Get("project-id") }) {
+			if branch := str(scan, "branch"); !slices.Contains(branches, branch) && strings.Contains(branch, name) {
+				branches = append(branches, branch)
+			}
+		}
+		writeJSON(w, http.StatusOK, branches)
+	})
+
+	s.mux.HandleFunc("GET /api/configuration/project", func(w http.ResponseWriter, r *http.Request) {
+		config, ok := s.projectConfig(r.URL.Query().Get("project-id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		writeJSON(w, http.StatusOK, config)
+	})
+	s.mux.HandleFunc("PATCH /api/configuration/project", func(w http.ResponseWriter, r *http.Request) {
+		config, ok := s.projectConfig(r.URL.Query().Get("project-id"))
+		var changes []object
+		if err := readJSON(r, &changes); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		} else if !ok {
+			writeError(w, http.StatusNotFound, "project not found")
+			return
+		}
+		for _, change := range changes {
+			for _, setting := range config {
+				if str(setting, "key") == str(change, "key") {
+					setting["value"] = change["value"]
+					setting["originLevel"] = "Project"
+				}
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	s.mux.HandleFunc("GET /api/applications", func(w http.ResponseWriter, r *http.Request) {
+		applications := s.applications.list(matchName(r, "name"))
+		writeJSON(w, http.StatusOK, object{"totalCount": len(s.applications.order), "filteredTotalCount": len(applications), "applications": s.applicationViews(page(r, applications))})
+	})
+	s.mux.HandleFunc("POST /api/applications", func(w http.ResponseWriter, r *http.Request) {
+		app, ok := readObject(w, r)
+		if !ok {
+			return
+		}
+		if _, exists := s.applications.find("name", str(app, "name")); exists {
+			writeError(w, http.StatusBadRequest, "application with this name already exists")
+			return
+		}
+		delete(app, "id")
+		app["createdAt"] = now()
+		app["updatedAt"] = now()
+		if _, ok := app["projectIds"]; !ok {
+			app["projectIds"] = []any{}
+		}
+		s.applications.add(app)
+		s.created(w, r, s.applicationView(app), false)
+	})
+	s.mux.HandleFunc("GET /api/applications/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if app, ok := s.applications.get(r.PathValue("id")); ok {
+			writeJSON(w, http.StatusOK, s.applicationView(app))
+		} else {
+			writeError(w, http.StatusNotFound, "application not found")
+		}
+	})
+	s.mux.HandleFunc("PUT /api/applications/{id}", func(w http.ResponseWriter, r *http.Request) {
+		app, ok := s.applications.get(r.PathValue("id"))
+		update, valid := readObject(w, r)
+		if !valid {
+			return
+		} else if !ok {
+			writeError(w, http.StatusNotFound, "application not found")
+			return
+		}
+		delete(update, "createdAt")
+		merge(app, update)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("DELETE /api/applications/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !s.applications.remove(r.PathValue("id")) {
+			writeError(w, http.StatusNotFound, "application not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (s *Server) createProject(w http.ResponseWriter, r *http.Request, applicationID string) {
+	project, ok := readObject(w, r)
+	if !ok {
+		return
+	}
+	if _, exists := s.projects.find("name", str(project, "name")); exists {
+		writeError(w, http.StatusBadRequest, "project with this name already exists")
+		return
+	}
+	var app object
+	if applicationID != "" {
+		if app, ok = s.applications.get(applicationID); !ok {
+			writeError(w, http.StatusNotFound, "application not found")
+			return
+		}
+	}
+
+	delete(project, "id")
+	project["createdAt"] = now()
+	project["updatedAt"] = now()
+	if _, ok := project["groups"]; !ok {
+		project["groups"] = []any{}
+	}
+	if _, ok := project["tags"]; !ok {
+		project["tags"] = object{}
+	}
+	s.projects.add(project)
+	if app != nil {
+		app["projectIds"] = toAny(append(strList(app, "projectIds"), str(project, "id")))
+	}
+
+	config := []object{}
+	for _, setting := range defaultProjectConfig {
+		config = append(config, object{"key": setting[0], "name": setting[0][strings.LastIndex(setting[0], ".")+1:], "category": "sast", "originLevel": "Tenant", "value": setting[1], "valuetype": "String", "allowOverride": true})
+	}
+	s.configs[str(project, "id")] = config
+	s.created(w, r, s.projectView(project), false)
+}
+
+func (s *Server) projectConfig(projectID string) ([]object, bool) {
+	config, ok := s.configs[projectID]
+	return config, ok
+}
+
+// the applications of a project follow from the projects of the applications and their rules
+func (s *Server) projectView(p object) object {
+	view := object{}
+	for k, v := range p {
+		view[k] = v
+	}
+	applicationIDs := []any{}
+	for _, app := range s.applications.list(nil) {
+		if slices.Contains(s.applicationProjects(app), str(p, "id")) {
+			applicationIDs = append(applicationIDs, app["id"])
+		}
+	}
+	view["applicationIds"] = applicationIDs
+	return view
+}
+
+func (s *Server) projectViews(projects []object) []object {
+	views := []object{}
+	for _, p := range projects {
+		views = append(views, s.projectView(p))
+	}
+	return views
+}
+
+func (s *Server) applicationView(app object) object {
+	view := object{}
+	for k, v := range app {
+		view[k] = v
+	}
+	view["projectIds"] = toAny(s.applicationProjects(app))
+	return view
+}
+
+func (s *Server) applicationViews(applications []object) []object {
+	views := []object{}
+	for _, app := range applications {
+		views = append(views, s.applicationView(app))
+	}
+	return views
+}
+
+// the projects added to the application, and the projects selected by its project.name.in rules
+func (s *Server) applicationProjects(app object) []string {
+	ids := strList(app, "projectIds")
+	if rules, ok := app["rules"].([]any); ok {
+		for _, rule := range rules {
+			rule, _ := rule.(map[string]any)
+			if str(rule, "type") != "project.name.in" {
+				continue
+			}
+			for _, name := range strings.Split(str(rule, "value"), ";") {
+				if p, ok := s.projects.find("name", strings.TrimSpace(name)); ok && !slices.Contains(ids, str(p, "id")) {
+					ids = append(ids, str(p, "id"))
+				}
+			}
+		}
+	}
+	return ids
+}
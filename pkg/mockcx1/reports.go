@@ -0,0 +1,88 @@
+package mockcx1
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func (s *Server) reportRoutes() {
+	// v1 reports name a single scan in data, v2 reports list the scans or projects as entities
+	s.mux.HandleFunc("POST /api/reports", func(w http.ResponseWriter, r *http.Request) {
+		s.createReport(w, r, 1)
+	})
+	s.mux.HandleFunc("POST /api/reports/v2", func(w http.ResponseWriter, r *http.Request) {
+		s.createReport(w, r, 2)
+	})
+	s.mux.HandleFunc("GET /api/reports/{id}", func(w http.ResponseWriter, r *http.Request) {
+		report, ok := s.reports.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "report not found")
+			return
+		}
+		status := "Requested"
+		if created, _ := time.Parse(time.RFC3339Nano, str(report, "createdAt")); time.Since(created) >= s.options.ScanDuration/5 {
+			status = "Completed"
+		}
+		response := object{"reportId": report["id"], "status": status}
+		if status == "Completed" {
+			response["url"] = fmt.Sprintf("%v/api/reports/%v/download", requestBaseURL(r), report["id"])
+		}
+		writeJSON(w, http.StatusOK, response)
+	})
+	s.mux.HandleFunc("GET /api/reports/{id}/download", func(w http.ResponseWriter, r *http.Request) {
+		report, ok := s.reports.get(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "report not found")
+			return
+		}
+		w.Write(reportContents(report))
+	})
+}
+
+func (s *Server) createReport(w http.ResponseWriter, r *http.Request, version int) {
+	report, ok := readObject(w, r)
+	if !ok {
+		return
+	}
+
+	scanIDs := []string{}
+	if data, ok := report["data"].(map[string]any); ok {
+		scanIDs = append(scanIDs, str(data, "scanId"))
+	}
+	if entities, ok := report["entities"].([]any); ok {
+		for _, entity := range entities {
+			entity, _ := entity.(map[string]any)
+			if str(entity, "entity") == "scan" {
+				scanIDs = append(scanIDs, toStrings(entity["ids"])...)
+			}
+		}
+	}
+	for _, id := range scanIDs {
+		if _, ok := s.scan(id); !ok {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("scan %v not found", id))
+			return
+		}
+	}
+
+	delete(report, "id")
+	report["version"] = version
+	report["createdAt"] = now()
+	s.reports.add(report)
+	writeJSON(w, http.StatusAccepted, object{"reportId": report["id"]})
+}
+
+// a placeholder document in the requested format
+func reportContents(report object) []byte {
+	switch strings.ToLower(str(report, "fileFormat")) {
+	case "json":
+		data, _ := json.Marshal(object{"reportId": report["id"], "reportName": report["reportName"], "generatedBy": "mockcx1"})
+		return data
+	case "csv":
+		return []byte("reportId,generatedBy\n" + str(report, "id") + ",mockcx1\n")
+	default:
+		return []byte("%PDF-1.4\n% mockcx1 report " + str(report, "id") + "\n%%EOF\n")
+	}
+}
@@ -0,0 +1,167 @@
+package mockcx1
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// the findings of every completed scan, as found by the example test suites in the SSBA sample project
+var sampleSASTResults = []struct {
+	Query, Group, Severity, SimilarityID, ResultHash string
+	QueryID                                          uint64
+}{
+	{"Stored_XSS", "Java_High_Risk", "HIGH", "-1870496421", "kN9hBYfbq1d4BvaSOulWfrXMgJY=", 10526212270892872000},
+	{"Reflected_XSS", "Java_High_Risk", "HIGH", "-1514176724", "3ObhMJHmrmiB0cVm8Do2cRY4ayo=", 5157925289005576664},
+	{"Reflected_XSS", "Java_High_Risk", "HIGH", "1318375675", "IvZcO7JJpOnbVhyqOYSTzW8eupk=", 5157925289005576664},
+	{"Reflected_XSS", "Java_High_Risk", "HIGH", "407623190", "JSJ06tyzcpoE/Si/hxtZ0c1Cu1k=", 5157925289005576664},
+	{"Parameter_Tampering", "Java_Medium_Threat", "MEDIUM", "-1732526860", "yuiHUdhdPjkIW60IP0Pf+P/WRdA=", 11536785653487744042},
+	{"Use_Of_Hardcoded_Password", "Java_Low_Visibility", "LOW", "715549665", "Jy3Va3r5mxuD0M/ER9R0nPIxPsg=", 8316282457722406127},
+}
+
+func (s *Server) newResults(scan object) []object {
+	engines := toStrings(scan["engines"])
+	results := []object{}
+	result := func(engine, similarityID, severity, description string, data object) {
+		results = append(results, object{
+			"type":         engine,
+			"id":           newID(),
+			"similarityId": similarityID,
+			"status":       "NEW",
+			"state":        "TO_VERIFY",
+			"severity":     severity,
+			"created":      scan["updatedAt"],
+			"firstFoundAt": scan["updatedAt"],
+			"foundAt":      scan["updatedAt"],
+			"firstScanId":  scan["id"],
+			"description":  description,
+			"data":         data,
+		})
+	}
+
+	if slices.Contains(engines, "sast") {
+		for _, r := range sampleSASTResults {
+			severity := r.Severity
+			if r.Query == "Stored_XSS" && s.flags["CVSS_V3_ENABLED"] {
+				severity = "CRITICAL"
+			}
+			result("sast", r.SimilarityID, severity, fmt.Sprintf("%v found in the mock scan", r.Query), object{
+				"queryId":      r.QueryID,
+				"queryName":    r.Query,
+				"group":        r.Group,
+				"resultHash":   r.ResultHash,
+				"languageName": "Java",
+				"nodes":        []object{{"fileName": "/src/main/java/ssba/Login.java", "line": 42, "column": 7, "name": "getParameter"}},
+			})
+		}
+	}
+	if slices.Contains(engines, "kics") {
+		result("kics", "073d0fe168d28e70e0bb8c3bd0dddf9cbf613a45f3a06f4b406e08a6cfa3f2bc", "LOW", "Ensure that HEALTHCHECK is being used", object{
+			"queryId":   "b03a748a-542d-44f4-bb86-9199ab4fd2d5",
+			"queryName": "Healthcheck Instruction Missing",
+			"group":     "Insecure Configurations",
+			"platform":  "Dockerfile",
+			"fileName":  "/Dockerfile",
+			"line":      1,
+		})
+	}
+	if slices.Contains(engines, "sca") {
+		result("sca", "CVE-2021-43980", "LOW", "The simplified implementation of blocking reads in Apache Tomcat could cause responses to be received by the wrong client", object{
+			"packageIdentifier":  "Maven-org.apache.tomcat.embed:tomcat-embed-core-9.0.46",
+			"recommendedVersion": "9.0.62",
+		})
+	}
+
+	// earlier triage of the project applies to the findings of new scans
+	for _, r := range results {
+		for _, predicate := range s.predicateHistory(str(r, "type"), str(scan, "projectId"), str(r, "similarityId")) {
+			applyPredicate(r, predicate)
+		}
+	}
+	return results
+}
+
+func (s *Server) scanSummary(scanID string) object {
+	summary := object{"scanId": scanID, "tenantId": s.tenantID()}
+	for engine, field := range map[string]string{"sast": "sastCounters", "kics": "kicsCounters", "sca": "scaCounters"} {
+		severities := map[string]int{}
+		total := 0
+		for _, r := range s.results[scanID] {
+			if str(r, "type") == engine {
+				severities[str(r, "severity")]++
+				total++
+			}
+		}
+		counters := []object{}
+		for _, severity := range sortedKeys(severities) {
+			counters = append(counters, object{"severity": severity, "counter": severities[severity]})
+		}
+		summary[field] = object{"severityCounters": counters, "totalCounter": total, "filesScannedCounter": 1}
+	}
+	return summary
+}
+
+// the triage changes are kept per project, keyed by engine and similarity ID
+func predicateKey(engine, projectID, similarityID string) string {
+	return strings.Join([]string{engine, projectID, similarityID}, "/")
+}
+
+func (s *Server) predicateHistory(engine, projectID, similarityID string) []object {
+	return s.predicates[predicateKey(engine, projectID, similarityID)]
+}
+
+func (s *Server) addPredicates(engine string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var predicates []object
+		if err := readJSON(r, &predicates); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		for _, predicate := range predicates {
+			similarityID := fmt.Sprint(predicate["similarityId"])
+			projectID := str(predicate, "projectId")
+			if _, ok := s.projects.get(projectID); !ok {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("project %v not found", projectID))
+				return
+			}
+			predicate["similarityId"] = similarityID
+			predicate["createdAt"] = now()
+			predicate["createdBy"] = "mock-admin"
+			key := predicateKey(engine, projectID, similarityID)
+			s.predicates[key] = append(s.predicates[key], predicate)
+
+			for _, scan := range s.scans.list(func(o object) bool { return str(o, "projectId") == projectID }) {
+				for _, result := range s.results[str(scan, "id")] {
+					if str(result, "type") == engine && str(result, "similarityId") == similarityID {
+						applyPredicate(result, predicate)
+					}
+				}
+			}
+		}
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (s *Server) getPredicates(engine string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		history := []object{}
+		for _, projectID := range queryList(r, "project-ids") {
+			predicates := s.predicateHistory(engine, projectID, r.PathValue("similarity"))
+			if predicates == nil {
+				predicates = []object{}
+			}
+			history = append(history, object{"projectId": projectID, "similarityId": r.PathValue("similarity"), "predicates": predicates, "totalCount": len(predicates)})
+		}
+		writeJSON(w, http.StatusOK, object{"predicateHistoryPerProject": history, "totalCount": len(history)})
+	}
+}
+
+func applyPredicate(result, predicate object) {
+	if state := str(predicate, "state"); state != "" {
+		result["state"] = state
+	}
+	if severity := str(predicate, "severity"); severity != "" {
+		result["severity"] = severity
+	}
+}
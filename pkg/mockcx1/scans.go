@@ -0,0 +1,305 @@
+package mockcx1
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	SCAN_QUEUED    = "Queued"
+	SCAN_RUNNING   = "Running"
+	SCAN_COMPLETED = "Completed"
+	SCAN_FAILED    = "Failed"
+	SCAN_CANCELED  = "Canceled"
+)
+
+func (s *Server) scanRoutes() {
+	s.mux.HandleFunc("POST /api/uploads", func(w http.ResponseWriter, r *http.Request) {
+		id := newID()
+		s.uploads[id] = nil
+		writeJSON(w, http.StatusOK, object{"url": fmt.Sprintf("%v/uploads/%v", requestBaseURL(r), id)})
+	})
+	// the pre-signed upload URL does not take the bearer token
+	s.mux.HandleFunc("PUT /uploads/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := s.uploads[r.PathValue("id")]; !ok {
+			writeError(w, http.StatusNotFound, "upload not found")
+			return
+		}
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.uploads[r.PathValue("id")] = data
+		w.WriteHeader(http.StatusOK)
+	})
+
+	s.mux.HandleFunc("POST /api/scans", s.createScan)
+	s.mux.HandleFunc("GET /api/scans", func(w http.ResponseWriter, r *http.Request) {
+		scans := s.filterScans(r)
+		writeJSON(w, http.StatusOK, object{"totalCount": len(s.scans.order), "filteredTotalCount": len(scans), "scans": page(r, scans)})
+	})
+	s.mux.HandleFunc("GET /api/projects/last-scan", func(w http.ResponseWriter, r *http.Request) {
+		last := object{}
+		for _, scan := range s.filterScans(r) {
+			if _, ok := last[str(scan, "projectId")]; !ok {
+				last[str(scan, "projectId")] = scan
+			}
+		}
+		writeJSON(w, http.StatusOK, last)
+	})
+	s.mux.HandleFunc("GET /api/scans/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if scan, ok := s.scan(r.PathValue("id")); ok {
+			writeJSON(w, http.StatusOK, scan)
+		} else {
+			writeError(w, http.StatusNotFound, "scan not found")
+		}
+	})
+	s.mux.HandleFunc("PATCH /api/scans/{id}", func(w http.ResponseWriter, r *http.Request) {
+		scan, ok := s.scan(r.PathValue("id"))
+		update, valid := readObject(w, r)
+		if !valid {
+			return
+		} else if !ok {
+			writeError(w, http.StatusNotFound, "scan not found")
+			return
+		}
+		if str(update, "status") == SCAN_CANCELED {
+			if status := str(scan, "status"); status != SCAN_QUEUED && status != SCAN_RUNNING {
+				writeError(w, http.StatusBadRequest, fmt.Sprintf("scan in status %v can not be canceled", status))
+				return
+			}
+			scan["canceled"] = true
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("DELETE /api/scans/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if !s.deleteScan(r.PathValue("id")) {
+			writeError(w, http.StatusNotFound, "scan not found")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+	s.mux.HandleFunc("GET /api/scans/{id}/workflow", func(w http.ResponseWriter, r *http.Request) {
+		scan, ok := s.scan(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "scan not found")
+			return
+		}
+		workflow := []object{{"source": "Orchestrator", "timestamp": scan["createdAt"], "info": "Scan created"}}
+		for _, detail := range scan["statusDetails"].([]object) {
+			workflow = append(workflow, object{"source": detail["name"], "timestamp": scan["updatedAt"], "info": fmt.Sprintf("Engine %v: %v", detail["name"], detail["status"])})
+		}
+		writeJSON(w, http.StatusOK, workflow)
+	})
+	s.mux.HandleFunc("GET /api/logs/{id}/{engine}", func(w http.ResponseWriter, r *http.Request) {
+		scan, ok := s.scan(r.PathValue("id"))
+		if !ok {
+			writeError(w, http.StatusNotFound, "scan not found")
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		fmt.Fprintf(w, "%v mock %v engine log for scan %v of project %v\n%v status: %v\n", scan["createdAt"], r.PathValue("engine"), scan["id"], scan["projectName"], scan["updatedAt"], scan["status"])
+	})
+
+	s.mux.HandleFunc("GET /api/results", func(w http.ResponseWriter, r *http.Request) {
+		results := s.results[r.URL.Query().Get("scan-id")]
+		if results == nil {
+			results = []object{}
+		}
+		writeJSON(w, http.StatusOK, object{"results": page(r, results), "totalCount": len(results)})
+	})
+	s.mux.HandleFunc("GET /api/scan-summary", func(w http.ResponseWriter, r *http.Request) {
+		summaries := []object{}
+		for _, id := range queryList(r, "scan-ids") {
+			if _, ok := s.scan(id); ok {
+				summaries = append(summaries, s.scanSummary(id))
+			}
+		}
+		writeJSON(w, http.StatusOK, object{"scansSummaries": summaries, "totalCount": len(summaries)})
+	})
+	s.mux.HandleFunc("GET /api/sast-scan-summary/aggregate", func(w http.ResponseWriter, r *http.Request) {
+		counts := map[string]object{}
+		for _, id := range append(queryList(r, "scan-ids"), queryList(r, "scan-id")...) {
+			for _, result := range s.results[id] {
+				if str(result, "type") != "sast" {
+					continue
+				}
+				data := result["data"].(object)
+				key := str(data, "queryName") + "/" + str(result, "severity")
+				if _, ok := counts[key]; !ok {
+					counts[key] = object{"queryID": data["queryId"], "queryName": data["queryName"], "severity": result["severity"], "language": data["languageName"], "count": 0}
+				}
+				counts[key]["count"] = counts[key]["count"].(int) + 1
+			}
+		}
+		summaries := []object{}
+		for _, key := range sortedKeys(counts) {
+			summaries = append(summaries, counts[key])
+		}
+		writeJSON(w, http.StatusOK, object{"summaries": summaries, "totalCount": len(summaries)})
+	})
+
+	for _, engine := range []string{"sast", "kics"} {
+		s.mux.HandleFunc("POST /api/"+engine+"-results-predicates", s.addPredicates(engine))
+		s.mux.HandleFunc("GET /api/"+engine+"-results-predicates/{similarity}", s.getPredicates(engine))
+	}
+}
+
+func (s *Server) createScan(w http.ResponseWriter, r *http.Request) {
+	request, ok := readObject(w, r)
+	if !ok {
+		return
+	}
+	projectRef, _ := request["project"].(map[string]any)
+	project, ok := s.projects.get(str(projectRef, "id"))
+	if !ok {
+		writeError(w, http.StatusBadRequest, "project not found")
+		return
+	}
+	handler, _ := request["handler"].(map[string]any)
+
+	configs, _ := request["config"].([]any)
+	engines := []any{}
+	for _, config := range configs {
+		config, _ := config.(map[string]any)
+		engine := str(config, "type")
+		if !slices.Contains(s.options.Engines, engine) && !(engine == "kics" && slices.Contains(s.options.Engines, "iac")) {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("engine %v is not licensed", engine))
+			return
+		}
+		engines = append(engines, engine)
+	}
+	if len(engines) == 0 {
+		writeError(w, http.StatusBadRequest, "no engines selected")
+		return
+	}
+
+	scan := object{
+		"status":       SCAN_QUEUED,
+		"projectId":    project["id"],
+		"projectName":  project["name"],
+		"branch":       str(handler, "branch"),
+		"createdAt":    now(),
+		"updatedAt":    now(),
+		"engines":      engines,
+		"tags":         request["tags"],
+		"initiator":    "mock-admin",
+		"userAgent":    r.UserAgent(),
+		"sourceOrigin": "API",
+		"metadata":     object{"type": request["type"], "handler": handler, "configs": request["config"]},
+	}
+	switch str(request, "type") {
+	case "upload":
+		scan["sourceType"] = "zip"
+		upload := str(handler, "uploadUrl") + str(handler, "uploadurl")
+		if data, ok := s.uploads[upload[strings.LastIndex(upload, "/")+1:]]; !ok || len(data) == 0 {
+			scan["failed"] = "the uploaded source is empty"
+		}
+	case "git":
+		scan["sourceType"] = "github"
+		if str(handler, "repoUrl") == "" {
+			writeError(w, http.StatusBadRequest, "repoUrl is required for git scans")
+			return
+		}
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported scan type %v", request["type"]))
+		return
+	}
+
+	s.scans.add(scan)
+	s.refreshScan(scan)
+	writeJSON(w, http.StatusCreated, scan)
+}
+
+// the scan with its status updated for the time since it was created
+func (s *Server) scan(id string) (object, bool) {
+	scan, ok := s.scans.get(id)
+	if ok {
+		s.refreshScan(scan)
+	}
+	return scan, ok
+}
+
+func (s *Server) refreshScan(scan object) {
+	status := str(scan, "status")
+	if status == SCAN_COMPLETED || status == SCAN_FAILED || status == SCAN_CANCELED {
+		return
+	}
+
+	created, _ := time.Parse(time.RFC3339Nano, str(scan, "createdAt"))
+	elapsed := time.Since(created)
+	switch {
+	case scan["canceled"] == true:
+		status = SCAN_CANCELED
+	case elapsed < s.options.ScanDuration/5:
+		status = SCAN_QUEUED
+	case elapsed < s.options.ScanDuration:
+		status = SCAN_RUNNING
+	case scan["failed"] != nil:
+		status = SCAN_FAILED
+	default:
+		status = SCAN_COMPLETED
+		s.results[str(scan, "id")] = s.newResults(scan)
+	}
+
+	if status != str(scan, "status") {
+		scan["status"] = status
+		scan["updatedAt"] = now()
+	}
+	details := []object{}
+	for _, engine := range toStrings(scan["engines"]) {
+		detail := object{"name": engine, "status": status, "details": ""}
+		if status == SCAN_FAILED {
+			detail["details"] = scan["failed"]
+		}
+		details = append(details, detail)
+	}
+	scan["statusDetails"] = details
+}
+
+func (s *Server) filterScans(r *http.Request) []object {
+	query := r.URL.Query()
+	projects := append(queryList(r, "project-id"), queryList(r, "project-ids")...)
+	statuses := append(queryList(r, "statuses"), queryList(r, "scan-status")...)
+	branches := append(queryList(r, "branch"), queryList(r, "branches")...)
+	engine := query.Get("engine")
+
+	scans := []object{}
+	for _, scan := range s.scans.list(nil) {
+		s.refreshScan(scan)
+		if (len(projects) == 0 || slices.Contains(projects, str(scan, "projectId"))) &&
+			(len(statuses) == 0 || slices.Contains(statuses, str(scan, "status"))) &&
+			(len(branches) == 0 || slices.Contains(branches, str(scan, "branch"))) &&
+			(engine == "" || slices.Contains(toStrings(scan["engines"]), engine)) {
+			scans = append(scans, scan)
+		}
+	}
+	// newest first, unless sorted by ascending creation
+	if !slices.Contains(queryList(r, "sort"), "+created_at") {
+		slices.Reverse(scans)
+	}
+	return scans
+}
+
+func (s *Server) deleteScan(id string) bool {
+	delete(s.results, id)
+	return s.scans.remove(id)
+}
+
+// a comma-separated or repeated query parameter
+func queryList(r *http.Request, name string) []string {
+	list := []string{}
+	for _, value := range r.URL.Query()[name] {
+		for _, v := range strings.Split(value, ",") {
+			if v != "" {
+				list = append(list, v)
+			}
+		}
+	}
+	return list
+}
@@ -0,0 +1,298 @@
+// Package mockcx1 is an in-memory imitation of the Cx1 and IAM REST APIs, with enough of the API to run the example test suites without a tenant.
+// It keeps objects as the JSON sent by the client, so only the fields which the mock itself needs are interpreted.
+package mockcx1
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Options struct {
+	Tenant       string          // default: mock
+	ClientID     string          // OAuth client which is accepted with ClientSecret, default: mock-client
+	ClientSecret string          // default: mock-secret
+	ScanDuration time.Duration   // time from a scan being queued to completing, default: 5s
+	Engines      []string        // licensed engines, default: sast, sca, kics, apisec, containers, microengines
+	Flags        map[string]bool // feature flags, in addition to the defaults
+	Version      string          // reported CxOne version, default: 3.40.0
+}
+
+// Server is an http.Handler, eg: for httptest.NewServer or http.ListenAndServe
+type Server struct {
+	options Options
+	secret  []byte // signs the access tokens and API keys
+	mux     *http.ServeMux
+	lock    sync.Mutex
+
+	projects     *collection
+	applications *collection
+	configs      map[string][]object // project configuration by project ID
+	groups       *collection
+	users        *collection
+	roles        *collection
+	clients      *collection
+	presets      *collection
+	uploads      map[string][]byte
+	scans        *collection
+	results      map[string][]object // by scan ID
+	predicates   map[string][]object // triage history, see predicateKey
+	reports      *collection
+	flags        map[string]bool
+
+	astApp  object // the client which holds the application roles
+	adminID string // the user behind API keys
+}
+
+func New(options Options) *Server {
+	if options.Tenant == "" {
+		options.Tenant = "mock"
+	}
+	if options.ClientID == "" {
+		options.ClientID = "mock-client"
+	}
+	if options.ClientSecret == "" {
+		options.ClientSecret = "mock-secret"
+	}
+	if options.ScanDuration == 0 {
+		options.ScanDuration = 5 * time.Second
+	}
+	if len(options.Engines) == 0 {
+		options.Engines = []string{"sast", "sca", "kics", "apisec", "containers", "microengines"}
+	}
+	if options.Version == "" {
+		options.Version = "3.40.0"
+	}
+
+	s := &Server{
+		options:      options,
+		secret:       make([]byte, 32),
+		mux:          http.NewServeMux(),
+		projects:     newCollection(),
+		applications: newCollection(),
+		configs:      map[string][]object{},
+		groups:       newCollection(),
+		users:        newCollection(),
+		roles:        newCollection(),
+		clients:      newCollection(),
+		presets:      newCollection(),
+		uploads:      map[string][]byte{},
+		scans:        newCollection(),
+		results:      map[string][]object{},
+		predicates:   map[string][]object{},
+		reports:      newCollection(),
+		flags:        map[string]bool{"NEW_PRESET_MANAGEMENT_ENABLED": true},
+	}
+	rand.Read(s.secret)
+	for name, value := range options.Flags {
+		s.flags[name] = value
+	}
+
+	s.seed()
+	s.routes()
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if (strings.HasPrefix(r.URL.Path, "/api/") || strings.HasPrefix(r.URL.Path, "/auth/admin/")) && !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "unauthorized")
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *Server) routes() {
+	s.authRoutes()
+	s.iamRoutes()
+	s.projectRoutes()
+	s.scanRoutes()
+	s.reportRoutes()
+	s.presetRoutes()
+
+	s.mux.HandleFunc("GET /api/versions", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, object{"CxOne": s.options.Version, "SAST": "9.7.0", "KICS": "2.1.0"})
+	})
+	s.mux.HandleFunc("GET /api/flags", func(w http.ResponseWriter, r *http.Request) {
+		flags := []object{}
+		for _, name := range sortedKeys(s.flags) {
+			flags = append(flags, object{"name": name, "status": s.flags[name]})
+		}
+		writeJSON(w, http.StatusOK, flags)
+	})
+	s.mux.HandleFunc("GET /api/flags/{name}", func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		writeJSON(w, http.StatusOK, object{"name": name, "status": s.flags[name]})
+	})
+}
+
+// objects are kept as the JSON which the client sent, with the id and timestamps added
+type object map[string]any
+
+type collection struct {
+	items map[string]object
+	order []string
+}
+
+func newCollection() *collection {
+	return &collection{items: map[string]object{}}
+}
+
+func (c *collection) add(o object) object {
+	id, _ := o["id"].(string)
+	if id == "" {
+		id = newID()
+		o["id"] = id
+	}
+	if _, ok := c.items[id]; !ok {
+		c.order = append(c.order, id)
+	}
+	c.items[id] = o
+	return o
+}
+
+func (c *collection) get(id string) (object, bool) {
+	o, ok := c.items[id]
+	return o, ok
+}
+
+func (c *collection) remove(id string) bool {
+	if _, ok := c.items[id]; !ok {
+		return false
+	}
+	delete(c.items, id)
+	c.order = slices.DeleteFunc(c.order, func(i string) bool { return i == id })
+	return true
+}
+
+// the objects for which match returns true, in the order they were created
+func (c *collection) list(match func(object) bool) []object {
+	list := []object{}
+	for _, id := range c.order {
+		if match == nil || match(c.items[id]) {
+			list = append(list, c.items[id])
+		}
+	}
+	return list
+}
+
+func (c *collection) find(field, value string) (object, bool) {
+	for _, id := range c.order {
+		if str(c.items[id], field) == value {
+			return c.items[id], true
+		}
+	}
+	return nil, false
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+func now() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
+
+func str(o object, field string) string {
+	s, _ := o[field].(string)
+	return s
+}
+
+func strList(o object, field string) []string {
+	return toStrings(o[field])
+}
+
+// copies the fields of the update over the object, except for the id
+func merge(o, update object) {
+	for k, v := range update {
+		if k != "id" {
+			o[k] = v
+		}
+	}
+	o["updatedAt"] = now()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func readJSON(r *http.Request, v any) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, v)
+}
+
+func readObject(w http.ResponseWriter, r *http.Request) (object, bool) {
+	o := object{}
+	if err := readJSON(r, &o); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid JSON: %s", err))
+		return nil, false
+	}
+	return o, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, object{"code": status, "message": message})
+}
+
+// applies the offset and limit query parameters of a list request
+func page(r *http.Request, list []object) []object {
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if first := r.URL.Query().Get("first"); first != "" {
+		offset, _ = strconv.Atoi(first)
+	}
+	offset = max(0, min(offset, len(list)))
+	list = list[offset:]
+
+	limit := r.URL.Query().Get("limit")
+	if limit == "" {
+		limit = r.URL.Query().Get("max")
+	}
+	if n, err := strconv.Atoi(limit); err == nil && n >= 0 && n < len(list) {
+		list = list[:n]
+	}
+	return list
+}
+
+// the name filter of list requests matches part of the name, names matches complete names
+func matchName(r *http.Request, field string) func(object) bool {
+	name := strings.ToLower(r.URL.Query().Get("name"))
+	names := r.URL.Query()["names"]
+	return func(o object) bool {
+		if name != "" && !strings.Contains(strings.ToLower(str(o, field)), name) {
+			return false
+		}
+		if len(names) > 0 && !slices.Contains(strings.Split(strings.Join(names, ","), ","), str(o, field)) {
+			return false
+		}
+		return true
+	}
+}
@@ -0,0 +1,125 @@
+package mockcx1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+type testClient struct {
+	t      *testing.T
+	server *httptest.Server
+	token  string
+}
+
+func newTestClient(t *testing.T) *testClient {
+	server := httptest.NewServer(New(Options{}))
+	t.Cleanup(server.Close)
+
+	c := &testClient{t: t, server: server}
+	resp, err := http.PostForm(server.URL+"/auth/realms/mock/protocol/openid-connect/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"mock-client"},
+		"client_secret": {"mock-secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil || token.AccessToken == "" {
+		t.Fatalf("failed to get a token: %v %v", resp.Status, err)
+	}
+	c.token = token.AccessToken
+	return c
+}
+
+// sends the body as JSON and decodes the response into result, if not nil
+func (c *testClient) do(method, path string, body, result any) int {
+	c.t.Helper()
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req, err := http.NewRequest(method, c.server.URL+path, bytes.NewReader(data))
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			c.t.Fatalf("%v %v: invalid JSON response: %s", method, path, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+func TestAuthentication(t *testing.T) {
+	mock := New(Options{})
+	server := httptest.NewServer(mock)
+	defer server.Close()
+
+	resp, err := http.PostForm(server.URL+"/auth/realms/mock/protocol/openid-connect/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"mock-client"},
+		"client_secret": {"wrong"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("token with a wrong secret returned %v", resp.Status)
+	}
+
+	c := &testClient{t: t, server: server}
+	if status := c.do(http.MethodGet, "/api/projects", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("request without a token returned %d", status)
+	}
+	c.token = mock.APIKey(server.URL)
+	if status := c.do(http.MethodGet, "/api/projects", nil, nil); status != http.StatusUnauthorized {
+		t.Errorf("request with an API key instead of an access token returned %d", status)
+	}
+}
+
+func TestProjectsPaging(t *testing.T) {
+	c := newTestClient(t)
+	for i := range 3 {
+		if status := c.do(http.MethodPost, "/api/projects", object{"name": fmt.Sprintf("project-%d", i)}, nil); status != http.StatusCreated && status != http.StatusOK {
+			t.Fatalf("creating a project returned %d", status)
+		}
+	}
+
+	for query, expected := range map[string]int{
+		"":                  3,
+		"?offset=1&limit=1": 1,
+		"?offset=2":         1,
+		"?offset=10":        0,
+		"?offset=-1":        3,
+		"?first=-5&max=2":   2,
+		"?limit=-1":         3,
+		"?name=project-1":   1,
+	} {
+		var list struct {
+			Projects []object `json:"projects"`
+		}
+		if status := c.do(http.MethodGet, "/api/projects"+query, nil, &list); status != http.StatusOK {
+			t.Errorf("listing projects with %q returned %d", query, status)
+		} else if len(list.Projects) != expected {
+			t.Errorf("listing projects with %q returned %d projects, expected %d", query, len(list.Projects), expected)
+		}
+	}
+}
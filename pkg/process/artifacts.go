@@ -0,0 +1,72 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+// the files are saved here while the tests run, as the test keys are only known once all tests have finished
+func artifactStagingDir(Config *TestConfig) string {
+	return filepath.Join(Config.ArtifactsDir, ".staging")
+}
+
+// WriteArtifacts moves the files downloaded by each test to <artifacts dir>/<test file name>/ and links them from the report
+func WriteArtifacts(reportData *Report, Config *TestConfig, logger *logrus.Logger) {
+	if Config.ArtifactsDir == "" {
+		return
+	}
+	defer os.RemoveAll(artifactStagingDir(Config))
+
+	for id := range reportData.Details {
+		d := &reportData.Details[id]
+		if len(d.Artifacts) == 0 {
+			continue
+		}
+
+		// replace the files of the previous run of this test
+		dir := filepath.Join(Config.ArtifactsDir, d.FileName())
+		if err := os.RemoveAll(dir); err != nil {
+			logger.Errorf("Failed to remove previous artifacts of test %v: %s", d.Key, err)
+		}
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			logger.Errorf("Failed to create artifact directory %v: %s", dir, err)
+			d.Artifacts = nil
+			continue
+		}
+
+		saved := []types.Artifact{}
+		for _, a := range d.Artifacts {
+			path := filepath.Join(dir, a.Name)
+			if err := os.Rename(a.Path, path); err != nil {
+				logger.Errorf("Failed to save artifact %v of test %v: %s", a.Name, d.Key, err)
+				continue
+			}
+			a.Path = reportRelativePath(Config.ReportName, path)
+			// a file downloaded again by the same test replaces the earlier one
+			saved = slices.DeleteFunc(saved, func(s types.Artifact) bool { return s.Name == a.Name })
+			saved = append(saved, a)
+		}
+		d.Artifacts = saved
+	}
+}
+
+// the path of a file as linked from the report, which is written next to the report name
+func reportRelativePath(reportName, path string) string {
+	reportDir, err := filepath.Abs(filepath.Dir(reportName))
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	rel, err := filepath.Rel(reportDir, abs)
+	if err != nil {
+		return filepath.ToSlash(abs)
+	}
+	return filepath.ToSlash(rel)
+}
@@ -0,0 +1,369 @@
+package process
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const (
+	CASSETTE_RECORD = "record"
+	CASSETTE_REPLAY = "replay"
+
+	cassetteFile      = "cassette.jsonl"
+	cassetteIgnored   = "{ignored}"
+	cassetteID        = "{id}"
+	cassetteSuffix    = "{E2E_RUN_SUFFIX}"
+	cassetteSignature = "cmVkYWN0ZWQ" // "redacted", the signature of recorded access tokens
+)
+
+var (
+	cassetteTimestamp      = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`)
+	cassetteRedactResponse = regexp.MustCompile(`("(?:refresh_token|id_token)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	cassetteAccessToken    = regexp.MustCompile(`("access_token"\s*:\s*"[\w-]+\.[\w-]+\.)[\w-]+"`)
+	cassetteUUID           = regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`)
+)
+
+// a request made through the client transport and the response it received
+type Interaction struct {
+	Method      string              `json:"Method"`
+	URL         string              `json:"URL"` // with credentials in the query redacted
+	RequestBody string              `json:"RequestBody,omitempty"`
+	ContentType string              `json:"ContentType,omitempty"`
+	Status      int                 `json:"Status"`
+	Header      map[string][]string `json:"Header,omitempty"`
+	Body        string              `json:"Body,omitempty"`
+	Base64      bool                `json:"Base64,omitempty"` // the Body is base64-encoded binary content, eg: a report or zip
+	Error       string              `json:"Error,omitempty"`
+}
+
+// Cassette records every HTTP interaction of a run to <dir>/cassette.jsonl, or serves the recorded responses back without network access
+// Requests are matched on method, host, path, query and body, after redacting credentials, replacing timestamps and
+// the values of the IgnoreFields. Identical requests receive the recorded responses in order, the last one repeating,
+// so that polling a scan sees it progress as it did when recorded. A request which was not recorded is matched to the
+// recorded requests which differ only in their IDs (eg: generated by the client), in the order they were recorded.
+// The E2E_RUN_SUFFIX is recorded as a placeholder, so that a cassette can be replayed with another suffix.
+type Cassette struct {
+	Mode         string
+	IgnoreFields []string // JSON fields and query parameters whose values differ between runs, eg: createdAt
+	Suffix       string   // the E2E_RUN_SUFFIX of the run
+
+	lock    sync.Mutex
+	file    *os.File
+	writer  *bufio.Writer
+	queues  map[string][]Interaction
+	served  map[string]int
+	byShape map[string][]Interaction // the recorded interactions by their key without IDs
+}
+
+func NewCassette(mode, dir string, ignoreFields []string) (*Cassette, error) {
+	c := &Cassette{Mode: mode, IgnoreFields: ignoreFields, Suffix: os.Getenv("E2E_RUN_SUFFIX")}
+	filename := filepath.Join(dir, cassetteFile)
+
+	switch mode {
+	case CASSETTE_RECORD:
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, err
+		}
+		file, err := os.Create(filename)
+		if err != nil {
+			return nil, err
+		}
+		c.file = file
+		c.writer = bufio.NewWriter(file)
+	case CASSETTE_REPLAY:
+		if err := c.load(filename); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("invalid cassette mode %v, options are: %v, %v", mode, CASSETTE_RECORD, CASSETTE_REPLAY)
+	}
+	return c, nil
+}
+
+func (c *Cassette) load(filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	c.queues = map[string][]Interaction{}
+	c.served = map[string]int{}
+	c.byShape = map[string][]Interaction{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 256*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var interaction Interaction
+		if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+			return fmt.Errorf("%v line %d: %s", filename, line, err)
+		}
+		u, err := url.Parse(interaction.URL)
+		if err != nil {
+			return fmt.Errorf("%v line %d: %s", filename, line, err)
+		}
+		key := c.key(interaction.Method, u, interaction.ContentType, interaction.RequestBody)
+		c.queues[key] = append(c.queues[key], interaction)
+		shape := cassetteShape(key)
+		c.byShape[shape] = append(c.byShape[shape], interaction)
+	}
+	return scanner.Err()
+}
+
+// Close writes the remaining recorded interactions
+func (c *Cassette) Close() error {
+	if c == nil || c.file == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err := c.writer.Flush(); err != nil {
+		c.file.Close()
+		return err
+	}
+	return c.file.Close()
+}
+
+// Transport replaces the base transport when replaying, and wraps it when recording
+func (c *Cassette) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &cassetteTransport{base: base, cassette: c}
+}
+
+type cassetteTransport struct {
+	base     http.RoundTripper
+	cassette *Cassette
+}
+
+func (t *cassetteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := cassetteReadBody(req)
+	if err != nil {
+		return nil, err
+	}
+	// a RoundTripper should not modify the request it was given
+	req = req.Clone(req.Context())
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	contentType := req.Header.Get("Content-Type")
+
+	if t.cassette.Mode == CASSETTE_REPLAY {
+		return t.cassette.replay(req, contentType, body)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	interaction := Interaction{
+		Method:      req.Method,
+		URL:         t.cassette.withoutSuffix(cassetteURL(req.URL).String()),
+		RequestBody: t.cassette.withoutSuffix(cassetteRequestBody(contentType, body)),
+		ContentType: contentType,
+	}
+	if err != nil {
+		interaction.Error = err.Error()
+		t.cassette.record(interaction)
+		return resp, err
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	interaction.Status = resp.StatusCode
+	interaction.Header = map[string][]string{}
+	for name, values := range resp.Header {
+		if !strings.EqualFold(name, "Set-Cookie") {
+			for _, value := range values {
+				interaction.Header[name] = append(interaction.Header[name], t.cassette.withoutSuffix(value))
+			}
+		}
+	}
+	if utf8.Valid(data) {
+		// the claims of access tokens are kept since the client reads them, eg: the tenant and license
+		body := cassetteAccessToken.ReplaceAllString(string(data), `${1}`+cassetteSignature+`"`)
+		body = cassetteRedactResponse.ReplaceAllString(body, `$1"`+harRedacted+`"`)
+		interaction.Body = t.cassette.withoutSuffix(body)
+	} else {
+		interaction.Body = base64.StdEncoding.EncodeToString(data)
+		interaction.Base64 = true
+	}
+	t.cassette.record(interaction)
+	return resp, nil
+}
+
+func (c *Cassette) record(interaction Interaction) {
+	line, err := json.Marshal(interaction)
+	if err != nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writer.Write(append(line, '\n'))
+	c.writer.Flush() // so that the interactions of an aborted run are kept
+}
+
+func (c *Cassette) replay(req *http.Request, contentType string, body []byte) (*http.Response, error) {
+	key := c.key(req.Method, req.URL, contentType, cassetteRequestBody(contentType, body))
+
+	c.lock.Lock()
+	queue := c.queues[key]
+	if len(queue) == 0 {
+		key = cassetteShape(key)
+		queue = c.byShape[key]
+	}
+	if len(queue) == 0 {
+		c.lock.Unlock()
+		return nil, fmt.Errorf("no recorded response in the cassette for %v %v", req.Method, cassetteURL(req.URL))
+	}
+	interaction := queue[min(c.served[key], len(queue)-1)]
+	c.served[key]++
+	c.lock.Unlock()
+
+	if interaction.Error != "" {
+		return nil, fmt.Errorf("%v (recorded)", interaction.Error)
+	}
+
+	data := []byte(c.withSuffix(interaction.Body))
+	if interaction.Base64 {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(interaction.Body); err != nil {
+			return nil, fmt.Errorf("invalid recorded response for %v %v: %s", req.Method, cassetteURL(req.URL), err)
+		}
+	}
+	header := http.Header{}
+	for name, values := range interaction.Header {
+		for _, value := range values {
+			header[name] = append(header[name], c.withSuffix(value))
+		}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %v", interaction.Status, http.StatusText(interaction.Status)),
+		StatusCode:    interaction.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// the normalized request which is matched against the recorded requests
+func (c *Cassette) key(method string, u *url.URL, contentType, body string) string {
+	query := c.normalizeValues(cassetteURL(u).Query())
+	return strings.Join([]string{method, u.Host, c.withoutSuffix(u.Path), query.Encode(), c.normalizeBody(contentType, body)}, " ")
+}
+
+// the key of a request with its IDs replaced, for requests which were not recorded with the same IDs
+func cassetteShape(key string) string {
+	return cassetteUUID.ReplaceAllString(key, cassetteID)
+}
+
+func (c *Cassette) withoutSuffix(s string) string {
+	if c.Suffix == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, c.Suffix, cassetteSuffix)
+}
+
+func (c *Cassette) withSuffix(s string) string {
+	return strings.ReplaceAll(s, cassetteSuffix, c.Suffix)
+}
+
+func (c *Cassette) normalizeValues(values url.Values) url.Values {
+	for name, list := range values {
+		for i := range list {
+			if c.ignored(name) {
+				list[i] = cassetteIgnored
+			} else {
+				list[i] = c.normalize(list[i])
+			}
+		}
+	}
+	return values
+}
+
+func (c *Cassette) normalizeBody(contentType, body string) string {
+	var value any
+	if strings.Contains(contentType, "json") && json.Unmarshal([]byte(body), &value) == nil {
+		// re-encoding also sorts the keys of objects
+		if data, err := json.Marshal(c.ignoreFields(value)); err == nil {
+			body = string(data)
+		}
+	} else if strings.Contains(contentType, "x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(body); err == nil {
+			return c.normalizeValues(values).Encode()
+		}
+	}
+	return c.normalize(body)
+}
+
+func (c *Cassette) ignoreFields(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for name, field := range v {
+			if c.ignored(name) {
+				v[name] = cassetteIgnored
+			} else {
+				v[name] = c.ignoreFields(field)
+			}
+		}
+	case []any:
+		for i := range v {
+			v[i] = c.ignoreFields(v[i])
+		}
+	}
+	return value
+}
+
+func (c *Cassette) ignored(name string) bool {
+	return slices.ContainsFunc(c.IgnoreFields, func(field string) bool { return strings.EqualFold(field, name) })
+}
+
+func (c *Cassette) normalize(s string) string {
+	return c.withoutSuffix(cassetteTimestamp.ReplaceAllString(s, cassetteIgnored))
+}
+
+func cassetteURL(u *url.URL) *url.URL {
+	redacted := *u
+	redacted.RawQuery = redactValues(u.Query()).Encode()
+	redacted.User = nil
+	return &redacted
+}
+
+func cassetteReadBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	defer req.Body.Close()
+	return io.ReadAll(req.Body)
+}
+
+// binary bodies, eg: uploaded zip files, are recorded as their hash
+func cassetteRequestBody(contentType string, body []byte) string {
+	if !utf8.Valid(body) {
+		return fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+	}
+	return redactBody(contentType, string(body))
+}
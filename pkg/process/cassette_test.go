@@ -0,0 +1,129 @@
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cxpsemea/cx1e2e/pkg/mockcx1"
+)
+
+type cassetteSession struct {
+	t      *testing.T
+	client *http.Client
+	token  string
+}
+
+func (s *cassetteSession) do(method, url string, body, result any) {
+	s.t.Helper()
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	req, err := http.NewRequest(method, url, bytes.NewReader(data))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		s.t.Fatalf("%v %v returned %v", method, url, resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		s.t.Fatalf("%v %v: invalid JSON response: %s", method, url, err)
+	}
+}
+
+// authenticates, creates a project named with the suffix and reads it back, with an ID generated by the client in the query
+func runCassetteSession(t *testing.T, cassette *Cassette, baseURL, requestID string) (token string, project map[string]any, list []map[string]any) {
+	s := &cassetteSession{t: t, client: &http.Client{Transport: cassette.Transport(nil)}}
+
+	resp, err := s.client.PostForm(baseURL+"/auth/realms/mock/protocol/openid-connect/token", url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {"mock-client"},
+		"client_secret": {"mock-secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tokens struct {
+		AccessToken string `json:"access_token"`
+	}
+	err = json.NewDecoder(resp.Body).Decode(&tokens)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.token = tokens.AccessToken
+
+	var created map[string]any
+	s.do(http.MethodPost, baseURL+"/api/projects", map[string]any{"name": "e2e-project" + cassette.Suffix}, &created)
+	s.do(http.MethodGet, fmt.Sprintf("%v/api/projects/%v", baseURL, created["id"]), nil, &project)
+
+	var projects struct {
+		Projects []map[string]any `json:"projects"`
+	}
+	s.do(http.MethodGet, fmt.Sprintf("%v/api/projects?name=e2e-project%v&requestId=%v", baseURL, cassette.Suffix, requestID), nil, &projects)
+	return s.token, project, projects.Projects
+}
+
+func TestCassetteRecordReplay(t *testing.T) {
+	server := httptest.NewServer(mockcx1.New(mockcx1.Options{}))
+	defer server.Close()
+	dir := t.TempDir()
+
+	t.Setenv("E2E_RUN_SUFFIX", "_recorded")
+	recorder, err := NewCassette(CASSETTE_RECORD, dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, recorded, _ := runCassetteSession(t, recorder, server.URL, "0d4c4f0a-8f5e-4c2a-9c0b-1f2e3d4c5b6a")
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, cassetteFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "_recorded") {
+		t.Errorf("cassette contains the E2E_RUN_SUFFIX of the recording")
+	}
+	claims := token[:strings.LastIndex(token, ".")]
+	if strings.Contains(string(data), token) || !strings.Contains(string(data), claims+"."+cassetteSignature) {
+		t.Errorf("cassette does not contain the access token with a redacted signature")
+	}
+
+	t.Setenv("E2E_RUN_SUFFIX", "_replayed")
+	player, err := NewCassette(CASSETTE_REPLAY, dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, replayed, list := runCassetteSession(t, player, server.URL, "7b1e2c3d-4e5f-4a6b-8c7d-9e0f1a2b3c4d")
+
+	if token != claims+"."+cassetteSignature {
+		t.Errorf("replayed access token %v does not have the recorded claims", token)
+	}
+	if replayed["id"] != recorded["id"] {
+		t.Errorf("replayed project ID %v, recorded %v", replayed["id"], recorded["id"])
+	}
+	if replayed["name"] != "e2e-project_replayed" {
+		t.Errorf("replayed project name %v does not have the suffix of the replay", replayed["name"])
+	}
+	if len(list) != 1 || list[0]["name"] != "e2e-project_replayed" {
+		t.Errorf("replayed project list with another request ID is %v", list)
+	}
+}
@@ -0,0 +1,75 @@
+package process
+
+import (
+	"github.com/cxpsemea/Cx1ClientGo"
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+type CleanupResult struct {
+	Deleted uint
+	Failed  uint
+}
+
+// Cleanup deletes the objects which are created by the tests in the configuration, eg: left over after an aborted run
+// test sets are processed in reverse order so that objects are removed before the objects they depend on
+func Cleanup(cx1client *Cx1ClientGo.Cx1Client, logger *logrus.Logger, Config *TestConfig, dryRun bool) CleanupResult {
+	var result CleanupResult
+	tl := types.NewThreadLogger(logger, 1)
+	tl.Sessions = types.NewAuditSessionManager()
+
+	for id := len(Config.Tests) - 1; id >= 0; id-- {
+		Config.Tests[id].Cleanup(cx1client, &tl, Config, dryRun, &result)
+	}
+
+	// deleting queries can open audit sessions
+	if len(tl.Sessions.Sessions) > 0 {
+		Config.Events.Emit(Config, Event{Event: EVT_CLEANUP, Action: "clear audit sessions"})
+	}
+	tl.Sessions.Clear(cx1client, logger)
+
+	logger.Infof("Cleanup complete: %d objects deleted, %d could not be deleted", result.Deleted, result.Failed)
+	return result
+}
+
+func (t *TestSet) Cleanup(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, Config *TestConfig, dryRun bool, result *CleanupResult) {
+	if len(t.SubTests) > 0 {
+		for id := len(t.SubTests) - 1; id >= 0; id-- {
+			t.SubTests[id].Cleanup(cx1client, logger, Config, dryRun, result)
+		}
+		return
+	}
+
+	for _, test := range t.GetTests(types.OP_DELETE) {
+		// scans are removed together with their project, deleting "the last scan" of a project could hit a scan not created by cx1e2e
+		if !test.IsType(types.OP_CREATE) || test.IsNegative() || test.GetModule() == types.MOD_SCAN {
+			continue
+		}
+
+		event := testEvent(EVT_CLEANUP, t.Name, types.OP_DELETE, test)
+		if dryRun {
+			logger.Infof("Would delete %v %v (created in test set '%v' [%v])", test.GetModule(), test.String(), t.Name, test.GetSource())
+			event.Action = "would delete"
+			Config.Events.Emit(Config, event)
+			continue
+		}
+
+		if crud, ok := test.(interface{ SetType(string) }); ok {
+			crud.SetType("D")
+		}
+
+		r := Run(cx1client, logger, types.OP_DELETE, t.Name, test, Config)
+		success := r.Result == TST_PASS
+		event.Action = "delete"
+		event.Success = &success
+		event.Reason = r.Reason
+		Config.Events.Emit(Config, event)
+		if success {
+			logger.Infof("Deleted %v %v", test.GetModule(), test.String())
+			result.Deleted++
+		} else {
+			logger.Warnf("Could not delete %v %v: %v", test.GetModule(), test.String(), r.Reason[0])
+			result.Failed++
+		}
+	}
+}
@@ -0,0 +1,219 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/cxpsemea/Cx1ClientGo"
+	"github.com/sirupsen/logrus"
+)
+
+// the result of running the test suite against one environment
+type EnvironmentReport struct {
+	Name   string
+	Error  string // set if the tests could not be run against this environment
+	Report Report
+}
+
+type ComparisonEnvironment struct {
+	Name    string                  `json:"Name"`
+	Target  string                  `json:"TestTarget"`
+	Version Cx1ClientGo.VersionInfo `json:"TargetVersions"`
+	Summary Counter                 `json:"Summary"`
+	Error   string                  `json:"Error,omitempty"`
+}
+
+type ComparisonTest struct {
+	Key     string            `json:"Key"`
+	ID      uint              `json:"ID"`
+	Name    string            `json:"Name"`
+	Source  string            `json:"Source"`
+	Test    string            `json:"Test"`
+	Results map[string]string `json:"Results"` // environment name -> PASS/FAIL/SKIP
+	Differs bool              `json:"Differs"`
+}
+
+type ComparisonReport struct {
+	Environments []ComparisonEnvironment `json:"Environments"`
+	Tests        []ComparisonTest        `json:"Tests"`
+}
+
+func ResultString(result int) string {
+	switch result {
+	case TST_PASS:
+		return "PASS"
+	case TST_FAIL:
+		return "FAIL"
+	case TST_SKIP:
+		return "SKIP"
+	case TST_SLOW:
+		return "SLOW"
+	}
+	return "UNKNOWN"
+}
+
+func prepareComparisonData(reports []EnvironmentReport) ComparisonReport {
+	var comparison ComparisonReport
+	tests := map[string]*ComparisonTest{}
+	keys := []string{}
+
+	for _, r := range reports {
+		comparison.Environments = append(comparison.Environments, ComparisonEnvironment{
+			Name:    r.Name,
+			Target:  r.Report.Settings.Target,
+			Version: r.Report.Settings.Version,
+			Summary: r.Report.Summary.Total,
+			Error:   r.Error,
+		})
+
+		for _, d := range r.Report.Details {
+			test, ok := tests[d.Key]
+			if !ok {
+				test = &ComparisonTest{Key: d.Key, ID: d.ID, Name: d.Name, Source: d.Source, Test: d.Test, Results: map[string]string{}}
+				tests[d.Key] = test
+				keys = append(keys, d.Key)
+			}
+			test.Results[r.Name] = ResultString(d.ResultType)
+		}
+	}
+
+	for _, key := range keys {
+		test := tests[key]
+		for _, r := range reports {
+			if test.Results[r.Name] != test.Results[reports[0].Name] {
+				test.Differs = true
+			}
+		}
+		comparison.Tests = append(comparison.Tests, *test)
+	}
+
+	return comparison
+}
+
+func OutputComparisonConsole(comparison *ComparisonReport, out io.Writer) {
+	fmt.Fprintln(out, "Environment comparison:")
+	for _, e := range comparison.Environments {
+		if e.Error != "" {
+			fmt.Fprintf(out, "%v: %v - not run: %v\n", e.Name, e.Target, e.Error)
+		} else {
+			fmt.Fprintf(out, "%v: %v (version: %v) - PASS %d, FAIL %d, SKIP %d\n", e.Name, e.Target, e.Version.String(), e.Summary.Pass, e.Summary.Fail, e.Summary.Skip)
+		}
+	}
+
+	fmt.Fprintln(out)
+	for _, t := range comparison.Tests {
+		if !t.Differs {
+			continue
+		}
+		results := []string{}
+		for _, e := range comparison.Environments {
+			results = append(results, fmt.Sprintf("%v=%v", e.Name, comparisonResult(&t, e.Name)))
+		}
+		fmt.Fprintf(out, "DIFF %v - %v: %v\n", t.Source, t.Test, strings.Join(results, ", "))
+	}
+}
+
+func comparisonResult(t *ComparisonTest, env string) string {
+	if result, ok := t.Results[env]; ok {
+		return result
+	}
+	return "-"
+}
+
+var comparisonFuncs = template.FuncMap{
+	"result": func(t ComparisonTest, env string) string {
+		return comparisonResult(&t, env)
+	},
+	"class": func(result string) string {
+		switch result {
+		case "PASS":
+			return "pass"
+		case "FAIL":
+			return "fail"
+		}
+		return "skip"
+	},
+}
+
+var comparisonTemplate = template.Must(template.New("comparison").Funcs(comparisonFuncs).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Cx1 environment comparison</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; border: 1px solid black; }
+th, td { border: 1px solid black; padding: 2px 4px; vertical-align: top; }
+tr.differs { background-color: #fff3cd; }
+.pass { color: green; }
+.fail { color: red; }
+.skip { color: orange; }
+</style>
+</head><body>
+<h2>Environments</h2>
+<table><tr><th>Environment</th><th>Target</th><th>Version</th><th>Pass</th><th>Fail</th><th>Skip</th></tr>
+{{range .Environments}}{{if .Error}}<tr><td>{{.Name}}</td><td>{{.Target}}</td><td colspan=4><span class="fail">Not run: {{.Error}}</span></td></tr>
+{{else}}<tr><td>{{.Name}}</td><td>{{.Target}}</td><td>{{.Version.String}}</td><td>{{.Summary.Pass}}</td><td>{{.Summary.Fail}}</td><td>{{.Summary.Skip}}</td></tr>
+{{end}}{{end}}</table><br>
+<h2>Results</h2>
+<table><tr><th>Test Set</th><th>Test</th>{{range .Environments}}<th>{{.Name}}</th>{{end}}</tr>
+{{$envs := .Environments}}{{range .Tests}}{{$test := .}}<tr{{if .Differs}} class="differs"{{end}}><td>{{.Name}}<br>({{.Source}})</td><td>{{.Test}}</td>{{range $envs}}{{$result := result $test .Name}}<td><span class="{{class $result}}">{{$result}}</span></td>{{end}}</tr>
+{{end}}</table>
+</body></html>`))
+
+func OutputComparisonHTML(reportName string, comparison *ComparisonReport) error {
+	report, err := os.Create(reportName)
+	if err != nil {
+		return err
+	}
+
+	defer report.Close()
+
+	if err = comparisonTemplate.Execute(report, comparison); err != nil {
+		return err
+	}
+
+	return report.Sync()
+}
+
+func OutputComparisonJSON(reportName string, comparison *ComparisonReport) error {
+	report, err := os.Create(reportName)
+	if err != nil {
+		return err
+	}
+
+	defer report.Close()
+
+	json, err := json.Marshal(*comparison)
+	if err != nil {
+		return err
+	}
+	_, err = report.Write(json)
+	if err != nil {
+		return err
+	}
+
+	return report.Sync()
+}
+
+func GenerateComparisonReport(reports []EnvironmentReport, logger *logrus.Logger, Config *TestConfig) ComparisonReport {
+	comparison := prepareComparisonData(reports)
+	OutputComparisonConsole(&comparison, Config.ConsoleWriter())
+
+	if strings.Contains(Config.ReportType, "html") {
+		filename := fmt.Sprintf("%v_comparison.html", Config.ReportName)
+		if err := OutputComparisonHTML(filename, &comparison); err != nil {
+			logger.Errorf("Failed to write HTML comparison report to %v: %s", filename, err)
+		}
+	}
+
+	if strings.Contains(Config.ReportType, "json") {
+		filename := fmt.Sprintf("%v_comparison.json", Config.ReportName)
+		if err := OutputComparisonJSON(filename, &comparison); err != nil {
+			logger.Errorf("Failed to write JSON comparison report to %v: %s", filename, err)
+		}
+	}
+
+	return comparison
+}
@@ -18,7 +18,10 @@ import (
 	"gopkg.in/yaml.v2"
 )
 
-var LastTestID uint = 0
+const (
+	DEFAULT_ENGINES    = "sast,sca,iac,apisec,2ms,containers"
+	CONFIG_YAML_SOURCE = "(yaml)" // the test source of tests loaded with LoadConfigYAML
+)
 
 func LoadConfig(logger *logrus.Logger, configPath string) (TestConfig, error) {
 	var conf TestConfig
@@ -28,9 +31,6 @@ func LoadConfig(logger *logrus.Logger, configPath string) (TestConfig, error) {
 		return conf, err
 	}
 
-	conf.ConfigPath, _ = filepath.Abs(file.Name())
-	currentRoot := filepath.Dir(file.Name())
-
 	defer file.Close()
 
 	fileBytes, err := io.ReadAll(file)
@@ -38,15 +38,26 @@ func LoadConfig(logger *logrus.Logger, configPath string) (TestConfig, error) {
 		return conf, err
 	}
 
-	re := regexp.MustCompile(`%([0-9a-zA-Z_]+)%`)
-	fileContents := string(fileBytes)
-	for matches := re.FindStringSubmatch(fileContents); len(matches) > 0; matches = re.FindStringSubmatch(fileContents) {
-		fileContents = strings.ReplaceAll(fileContents, fmt.Sprintf("%%%v%%", matches[1]), os.Getenv(matches[1]))
+	conf, err = parseConfig(logger, fileBytes, configPath, filepath.Dir(file.Name()))
+	conf.ConfigPath, _ = filepath.Abs(file.Name())
+	return conf, err
+}
+
+// LoadConfigYAML reads a test configuration which is not stored in a file, eg: embedded in a program using the Runner
+// File, ZipFile and ProjectMapFile references are resolved relative to the root directory
+func LoadConfigYAML(logger *logrus.Logger, data []byte, root string) (TestConfig, error) {
+	if root == "" {
+		root = "."
 	}
+	return parseConfig(logger, data, CONFIG_YAML_SOURCE, root)
+}
+
+func parseConfig(logger *logrus.Logger, data []byte, configPath, currentRoot string) (TestConfig, error) {
+	var conf TestConfig
 
-	d := yaml.NewDecoder(strings.NewReader(fileContents))
+	d := yaml.NewDecoder(strings.NewReader(substituteEnv(string(data))))
 
-	err = d.Decode(&conf)
+	err := d.Decode(&conf)
 	if err != nil {
 		return conf, err
 	}
@@ -68,35 +79,38 @@ func LoadConfig(logger *logrus.Logger, configPath string) (TestConfig, error) {
 				return conf, fmt.Errorf("error loading sub-test %v: %s", set.File, err)
 			}
 			logger.Debugf("Loaded sub-config from %v", conf2.ConfigPath)
+			conf.PreExisting.Merge(&conf2.PreExisting)
 			//testSet = append(testSet, conf2.Tests...)
 			conf.Tests[tid].SubTests = conf2.Tests
 			conf.Tests[tid].Thread = set.Thread
 		} else {
-			for id, scan := range set.Scans {
-				logger.Tracef(" - Checking Scan TestSet %v for file references", set.Name)
-				if scan.ZipFile != "" {
-					filePath, err := getFilePath(currentRoot, scan.ZipFile)
-					if err != nil {
-						return conf, fmt.Errorf("error locating scan zipfile %v", scan.ZipFile)
+			for _, test := range set.AllTests() {
+				switch test := test.(type) {
+				case *types.ScanCRUD:
+					logger.Tracef(" - Checking Scan TestSet %v for file references", set.Name)
+					if test.ZipFile != "" {
+						filePath, err := getFilePath(currentRoot, test.ZipFile)
+						if err != nil {
+							return conf, fmt.Errorf("error locating scan zipfile %v", test.ZipFile)
+						}
+						test.ZipFile = filePath
 					}
-					set.Scans[id].ZipFile = filePath
-				}
-			}
-			for id, imp := range set.Imports {
-				logger.Tracef(" - Checking Import TestSet %v for file references", set.Name)
-				if imp.ZipFile != "" {
-					filePath, err := getFilePath(currentRoot, imp.ZipFile)
-					if err != nil {
-						return conf, fmt.Errorf("error locating import zipfile %v", imp.ZipFile)
+				case *types.ImportCRUD:
+					logger.Tracef(" - Checking Import TestSet %v for file references", set.Name)
+					if test.ZipFile != "" {
+						filePath, err := getFilePath(currentRoot, test.ZipFile)
+						if err != nil {
+							return conf, fmt.Errorf("error locating import zipfile %v", test.ZipFile)
+						}
+						test.ZipFile = filePath
 					}
-					set.Imports[id].ZipFile = filePath
-				}
-				if imp.ProjectMapFile != "" {
-					filePath, err := getFilePath(currentRoot, imp.ProjectMapFile)
-					if err != nil {
-						return conf, fmt.Errorf("error locating import ProjectMapFile %v", imp.ProjectMapFile)
+					if test.ProjectMapFile != "" {
+						filePath, err := getFilePath(currentRoot, test.ProjectMapFile)
+						if err != nil {
+							return conf, fmt.Errorf("error locating import ProjectMapFile %v", test.ProjectMapFile)
+						}
+						test.ProjectMapFile = filePath
 					}
-					set.Imports[id].ProjectMapFile = filePath
 				}
 			}
 			//testSet = append(testSet, set)
@@ -108,6 +122,15 @@ func LoadConfig(logger *logrus.Logger, configPath string) (TestConfig, error) {
 	return conf, nil
 }
 
+// replaces %NAME% with the value of the environment variable NAME
+func substituteEnv(fileContents string) string {
+	re := regexp.MustCompile(`%([0-9a-zA-Z_]+)%`)
+	for matches := re.FindStringSubmatch(fileContents); len(matches) > 0; matches = re.FindStringSubmatch(fileContents) {
+		fileContents = strings.ReplaceAll(fileContents, fmt.Sprintf("%%%v%%", matches[1]), os.Getenv(matches[1]))
+	}
+	return fileContents
+}
+
 func (t *TestConfig) IsValid(logger *logrus.Logger) bool {
 	failedTests := false
 	for _, test := range t.Tests {
@@ -115,6 +138,21 @@ func (t *TestConfig) IsValid(logger *logrus.Logger) bool {
 			failedTests = true
 		}
 	}
+	for _, budget := range t.DurationBudgets {
+		if budget.Module == "" {
+			logger.Errorf("Invalid duration budget: no Module specified")
+			failedTests = true
+		} else if err := budget.Validate(); err != nil {
+			logger.Errorf("Invalid duration budget for module %v: %s", budget.Module, err)
+			failedTests = true
+		}
+	}
+	for _, notification := range t.Notifications {
+		if err := notification.Validate(); err != nil {
+			logger.Errorf("Invalid notification: %s", err)
+			failedTests = true
+		}
+	}
 	return !failedTests
 }
 
@@ -125,23 +163,25 @@ func (t *TestConfig) InitTestIDs() {
 	// 1st: subtests
 	// 2nd: CRU ops in order
 	// last: D ops in reverse order
+	// the IDs start from 1 for every config, so that separate runs (eg: per environment) get the same IDs
+	var lastID uint
 	for id := range t.Tests {
-		t.Tests[id].InitTestIDs()
+		t.Tests[id].InitTestIDs(&lastID)
 	}
 }
 
-func (t *TestSet) InitTestIDs() {
+func (t *TestSet) InitTestIDs(lastID *uint) {
 	// 1st: subtests
 	// 2nd: CRU ops in order
 	// last: D ops in reverse order
 	for id := range t.SubTests {
-		t.SubTests[id].InitTestIDs()
+		t.SubTests[id].InitTestIDs(lastID)
 	}
 
-	t.InitTestIDsCRUD(types.OP_CREATE)
-	t.InitTestIDsCRUD(types.OP_READ)
-	t.InitTestIDsCRUD(types.OP_UPDATE)
-	t.InitTestIDsCRUD(types.OP_DELETE)
+	t.InitTestIDsCRUD(types.OP_CREATE, lastID)
+	t.InitTestIDsCRUD(types.OP_READ, lastID)
+	t.InitTestIDsCRUD(types.OP_UPDATE, lastID)
+	t.InitTestIDsCRUD(types.OP_DELETE, lastID)
 }
 
 func (c TestConfig) PrintTests() {
@@ -154,128 +194,40 @@ type AllCRUD interface {
 	types.AccessAssignmentCRUD | types.AnalyticsCRUD
 }
 
+// PrintTests prints the tests in the same order as they would be run by a single thread
 func (t TestSet) PrintTests() {
-	for id := range t.SubTests {
-		t.SubTests[id].PrintTests()
+	thread := "any"
+	if t.Thread != 0 {
+		thread = fmt.Sprintf("T%d", t.Thread)
 	}
 
-	for id2 := range t.AccessAssignments {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.AccessAssignments[id2].TestID, t.AccessAssignments[id2].String())
-	}
-	for id2 := range t.Analytics {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Analytics[id2].TestID, t.Analytics[id2].String())
-	}
-	for id2 := range t.Applications {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Applications[id2].TestID, t.Applications[id2].String())
-	}
-	for id2 := range t.Branches {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Branches[id2].TestID, t.Branches[id2].String())
-	}
-	for id2 := range t.Clients {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Clients[id2].TestID, t.Clients[id2].String())
-	}
-	for id2 := range t.Flags {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Flags[id2].TestID, t.Flags[id2].String())
-	}
-	for id2 := range t.Groups {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Groups[id2].TestID, t.Groups[id2].String())
-	}
-	for id2 := range t.Imports {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Imports[id2].TestID, t.Imports[id2].String())
+	if t.Wait > 0 {
+		fmt.Printf("[%v] wait %d seconds - '%v' [%v]\n", thread, t.Wait, t.Name, t.TestSource)
 	}
-	for id2 := range t.Presets {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Presets[id2].TestID, t.Presets[id2].String())
-	}
-	for id2 := range t.Projects {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Projects[id2].TestID, t.Projects[id2].String())
-	}
-	for id2 := range t.Queries {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Queries[id2].TestID, t.Queries[id2].String())
-	}
-	for id2 := range t.Reports {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Reports[id2].TestID, t.Reports[id2].String())
-	}
-	for id2 := range t.Results {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Results[id2].TestID, t.Results[id2].String())
-	}
-	for id2 := range t.Roles {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Roles[id2].TestID, t.Roles[id2].String())
-	}
-	for id2 := range t.Scans {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Scans[id2].TestID, t.Scans[id2].String())
+
+	if len(t.SubTests) > 0 {
+		for id := range t.SubTests {
+			t.SubTests[id].PrintTests()
+		}
+		return
 	}
-	for id2 := range t.Users {
-		fmt.Printf("[%d] [%d] %v\n", t.Thread, t.Users[id2].TestID, t.Users[id2].String())
+
+	for _, CRUD := range []string{types.OP_CREATE, types.OP_READ, types.OP_UPDATE, types.OP_DELETE} {
+		for _, test := range t.GetTests(CRUD) {
+			if test.IsType(CRUD) {
+				testType := "Test"
+				if test.IsNegative() {
+					testType = "Negative-Test"
+				}
+				fmt.Printf("[%v] #%d - %v %v %v '%v' - %v [%v]\n", thread, test.GetID(), CRUD, test.GetModule(), testType, t.Name, test.String(), test.GetSource())
+			}
+		}
 	}
 }
 
 func (t *TestSet) Init() {
-	for id2 := range t.AccessAssignments {
-		t.AccessAssignments[id2].TestSource = t.TestSource
-		t.AccessAssignments[id2].Thread = t.Thread
-	}
-	for id2 := range t.Analytics {
-		t.Analytics[id2].TestSource = t.TestSource
-		t.Analytics[id2].Thread = t.Thread
-	}
-	for id2 := range t.Applications {
-		t.Applications[id2].TestSource = t.TestSource
-		t.Applications[id2].Thread = t.Thread
-	}
-	for id2 := range t.Branches {
-		t.Branches[id2].TestSource = t.TestSource
-		t.Branches[id2].Thread = t.Thread
-	}
-	for id2 := range t.Clients {
-		t.Clients[id2].TestSource = t.TestSource
-		t.Clients[id2].Thread = t.Thread
-	}
-	for id2 := range t.Flags {
-		t.Flags[id2].TestSource = t.TestSource
-		t.Flags[id2].Thread = t.Thread
-	}
-	for id2 := range t.Groups {
-		t.Groups[id2].TestSource = t.TestSource
-		t.Groups[id2].Thread = t.Thread
-	}
-	for id2 := range t.Imports {
-		t.Imports[id2].TestSource = t.TestSource
-		t.Imports[id2].Thread = t.Thread
-	}
-	for id2 := range t.Presets {
-		t.Presets[id2].TestSource = t.TestSource
-		t.Presets[id2].Thread = t.Thread
-	}
-	for id2 := range t.Projects {
-		t.Projects[id2].TestSource = t.TestSource
-		t.Projects[id2].Thread = t.Thread
-	}
-	for id2 := range t.Queries {
-		t.Queries[id2].TestSource = t.TestSource
-		t.Queries[id2].Thread = t.Thread
-	}
-	for id2 := range t.Reports {
-		t.Reports[id2].TestSource = t.TestSource
-		t.Reports[id2].Thread = t.Thread
-	}
-	for id2 := range t.Results {
-		t.Results[id2].TestSource = t.TestSource
-		t.Results[id2].Thread = t.Thread
-		if t.Results[id2].Number == 0 {
-			t.Results[id2].Number = 1
-		}
-	}
-	for id2 := range t.Roles {
-		t.Roles[id2].TestSource = t.TestSource
-		t.Roles[id2].Thread = t.Thread
-	}
-	for id2 := range t.Scans {
-		t.Scans[id2].TestSource = t.TestSource
-		t.Scans[id2].Thread = t.Thread
-	}
-	for id2 := range t.Users {
-		t.Users[id2].TestSource = t.TestSource
-		t.Users[id2].Thread = t.Thread
+	for _, test := range t.AllTests() {
+		test.SetSource(t.TestSource, t.Thread)
 	}
 
 	for id2 := range t.SubTests {
@@ -286,217 +238,19 @@ func (t *TestSet) Init() {
 	}
 }
 
-func (t *TestSet) InitTestIDsCRUD(CRUD string) {
-	if CRUD != types.OP_DELETE {
-		for id := range t.Flags {
-			if t.Flags[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Flags[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Analytics {
-			if t.Analytics[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Analytics[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Imports {
-			if t.Imports[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Imports[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Groups {
-			if t.Groups[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Groups[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Applications {
-			if t.Applications[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Applications[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Projects {
-			if t.Projects[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Projects[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Roles {
-			if t.Roles[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Roles[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Users {
-			if t.Users[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Users[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Clients {
-			if t.Clients[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Clients[id].TestID = LastTestID
-			}
-		}
-		for id := range t.AccessAssignments {
-			if t.AccessAssignments[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.AccessAssignments[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Queries {
-			if t.Queries[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Queries[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Presets {
-			if t.Presets[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Presets[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Scans {
-			if t.Scans[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Scans[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Branches {
-			if t.Branches[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Branches[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Results {
-			if t.Results[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Results[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Reports {
-			if t.Reports[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Reports[id].TestID = LastTestID
-			}
-		}
-	} else { // in reverse order for DELETE
-		for id := range t.Scans {
-			if t.Scans[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Scans[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Presets {
-			if t.Presets[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Presets[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Queries {
-			if t.Queries[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Queries[id].TestID = LastTestID
-			}
-		}
-		for id := range t.AccessAssignments {
-			if t.AccessAssignments[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.AccessAssignments[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Clients {
-			if t.Clients[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Clients[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Users {
-			if t.Users[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Users[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Roles {
-			if t.Roles[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Roles[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Projects {
-			if t.Projects[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Projects[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Applications {
-			if t.Applications[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Applications[id].TestID = LastTestID
-			}
-		}
-		for id := range t.Groups {
-			if t.Groups[id].CRUDTest.IsType(CRUD) {
-				LastTestID++
-				t.Groups[id].TestID = LastTestID
-			}
+func (t *TestSet) InitTestIDsCRUD(CRUD string, lastID *uint) {
+	for _, test := range t.GetTests(CRUD) {
+		if test.IsType(CRUD) {
+			*lastID++
+			test.SetID(*lastID)
 		}
 	}
 }
 
 func (t *TestSet) SetActiveThread(thread int) {
 	t.ActiveThread = thread
-	for id2 := range t.AccessAssignments {
-		t.AccessAssignments[id2].ActiveThread = thread
-	}
-	for id2 := range t.Analytics {
-		t.Analytics[id2].ActiveThread = thread
-	}
-	for id2 := range t.Applications {
-		t.Applications[id2].ActiveThread = thread
-	}
-	for id2 := range t.Branches {
-		t.Branches[id2].ActiveThread = thread
-	}
-	for id2 := range t.Clients {
-		t.Clients[id2].ActiveThread = thread
-	}
-	for id2 := range t.Flags {
-		t.Flags[id2].ActiveThread = thread
-	}
-	for id2 := range t.Groups {
-		t.Groups[id2].ActiveThread = thread
-	}
-	for id2 := range t.Imports {
-		t.Imports[id2].ActiveThread = thread
-	}
-	for id2 := range t.Presets {
-		t.Presets[id2].ActiveThread = thread
-	}
-	for id2 := range t.Projects {
-		t.Projects[id2].ActiveThread = thread
-	}
-	for id2 := range t.Queries {
-		t.Queries[id2].ActiveThread = thread
-	}
-	for id2 := range t.Reports {
-		t.Reports[id2].ActiveThread = thread
-	}
-	for id2 := range t.Results {
-		t.Results[id2].ActiveThread = thread
-	}
-	for id2 := range t.Roles {
-		t.Roles[id2].ActiveThread = thread
-	}
-	for id2 := range t.Scans {
-		t.Scans[id2].ActiveThread = thread
-	}
-	for id2 := range t.Users {
-		t.Users[id2].ActiveThread = thread
+	for _, test := range t.AllTests() {
+		test.SetActiveThread(thread)
 	}
 
 	for id2 := range t.SubTests {
@@ -507,83 +261,8 @@ func (t *TestSet) SetActiveThread(thread int) {
 func (t TestSet) IsValid(logger *logrus.Logger) bool {
 	failedTests := false
 
-	for id2 := range t.AccessAssignments {
-		if !isTestValid(&t.AccessAssignments[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Analytics {
-		if !isTestValid(&t.Analytics[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Applications {
-		if !isTestValid(&t.Applications[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Branches {
-		if !isTestValid(&t.Branches[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Clients {
-		if !isTestValid(&t.Clients[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Flags {
-		if !isTestValid(&t.Flags[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Groups {
-		if !isTestValid(&t.Groups[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Imports {
-		if !isTestValid(&t.Imports[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Presets {
-		if !isTestValid(&t.Presets[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Projects {
-		if !isTestValid(&t.Projects[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Queries {
-		if !isTestValid(&t.Queries[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Reports {
-		if !isTestValid(&t.Reports[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Results {
-		if !isTestValid(&t.Results[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Roles {
-		if !isTestValid(&t.Roles[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Scans {
-		if !isTestValid(&t.Scans[id2], logger) {
-			failedTests = true
-		}
-	}
-	for id2 := range t.Users {
-		if !isTestValid(&t.Users[id2], logger) {
+	for _, test := range t.AllTests() {
+		if !isTestValid(test, logger) {
 			failedTests = true
 		}
 	}
@@ -605,6 +284,10 @@ func isTestValid(runner TestRunner, logger *logrus.Logger) bool {
 				logger.Infof("Test [%v] %v %v is invalid: %v", runner.GetSource(), runner.String(), test, err)
 				failedTest = true
 			}
+			if err := runner.GetBudget(test).Validate(); err != nil {
+				logger.Infof("Test [%v] %v %v has an invalid duration budget: %v", runner.GetSource(), runner.String(), test, err)
+				failedTest = true
+			}
 		}
 	}
 
@@ -612,23 +295,7 @@ func isTestValid(runner TestRunner, logger *logrus.Logger) bool {
 }
 
 func (t TestSet) GetTestCount() int {
-	var count int = 0
-
-	count += len(t.AccessAssignments)
-	count += len(t.Applications)
-	count += len(t.Branches)
-	count += len(t.Clients)
-	count += len(t.Flags)
-	count += len(t.Groups)
-	count += len(t.Imports)
-	count += len(t.Presets)
-	count += len(t.Projects)
-	count += len(t.Queries)
-	count += len(t.Reports)
-	count += len(t.Results)
-	count += len(t.Roles)
-	count += len(t.Scans)
-	count += len(t.Users)
+	count := len(t.AllTests())
 
 	for id := range t.SubTests {
 		count += t.SubTests[id].GetTestCount()
@@ -645,7 +312,47 @@ func (o TestConfig) GetTestCount() int {
 	return count
 }
 
+// GetBudget returns the duration budget of the test, or the default budget of its module for this CRUD operation
+// a module budget for specific operations (eg: Test: C) takes precedence over one without
+func (o *TestConfig) GetBudget(test TestRunner, CRUD string) types.DurationBudget {
+	if budget := test.GetBudget(CRUD); budget.IsSet() {
+		return budget
+	}
+
+	var budget types.DurationBudget
+	for _, b := range o.DurationBudgets {
+		if b.Module != test.GetModule() {
+			continue
+		}
+		if b.Test == "" {
+			if !budget.IsSet() {
+				budget = b.DurationBudget
+			}
+		} else if strings.Contains(b.Test, CRUD[:1]) {
+			return b.DurationBudget
+		}
+	}
+	return budget
+}
+
+// ConsoleWriter is where the summary, comparison and inline reports are printed
+func (o TestConfig) ConsoleWriter() io.Writer {
+	if o.Console == nil {
+		return os.Stdout
+	}
+	return o.Console
+}
+
 func (o TestConfig) CreateHTTPClient(logger *logrus.Logger) (*http.Client, error) {
+	return o.createHTTPClient(logger, nil)
+}
+
+// CreateThreadHTTPClient creates the HTTP client of a thread, its requests are recorded for the test running on the thread
+func (o TestConfig) CreateThreadHTTPClient(logger *types.ThreadLogger) (*http.Client, error) {
+	return o.createHTTPClient(logger.GetLogger(), logger)
+}
+
+func (o TestConfig) createHTTPClient(logger *logrus.Logger, thread *types.ThreadLogger) (*http.Client, error) {
 	httpClient := &http.Client{}
 	transport := &http.Transport{}
 
@@ -676,9 +383,42 @@ func (o TestConfig) CreateHTTPClient(logger *logrus.Logger) (*http.Client, error
 	}
 
 	httpClient.Transport = transport
+	// innermost, so that replayed requests are still recorded by the telemetry and traced
+	if o.Cassette != nil {
+		httpClient.Transport = o.Cassette.Transport(httpClient.Transport)
+	}
+	if o.Telemetry != nil {
+		httpClient.Transport = o.Telemetry.Transport(httpClient.Transport, thread)
+	}
+	// outermost, so that the traceparent header is included in the captured HAR files
+	if o.Tracer != nil {
+		httpClient.Transport = o.Tracer.Transport(httpClient.Transport, thread)
+	}
 	return httpClient, nil
 }
 
+// ParseEngines enables the engines in a comma-separated list, eg: sast,sca
+func ParseEngines(engines string) types.EnabledEngines {
+	var enabled types.EnabledEngines
+	for _, e := range strings.Split(strings.ToLower(engines), ",") {
+		switch strings.TrimSpace(e) {
+		case "sast":
+			enabled.SAST = true
+		case "sca":
+			enabled.SCA = true
+		case "kics", "iac":
+			enabled.IAC = true
+		case "apisec":
+			enabled.APISEC = true
+		case "2ms", "secrets":
+			enabled.Secrets = true
+		case "containers":
+			enabled.Containers = true
+		}
+	}
+	return enabled
+}
+
 func getFilePath(currentRoot, file string) (string, error) {
 	osPath := filepath.FromSlash(file)
 	//logger.Debugf("Trying to find config file %v, current root is %v", osPath, currentRoot)
@@ -0,0 +1,33 @@
+package process
+
+import (
+	"testing"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+)
+
+func TestGetBudget(t *testing.T) {
+	config := TestConfig{
+		DurationBudgets: []ModuleBudget{
+			{Module: types.MOD_PROJECT, TestBudget: types.TestBudget{DurationBudget: types.DurationBudget{MaxDuration: 10}}},
+			{Module: types.MOD_PROJECT, TestBudget: types.TestBudget{Test: "C", DurationBudget: types.DurationBudget{MaxDuration: 20}}},
+		},
+	}
+	test := &types.ProjectCRUD{}
+	test.Test = "CRUD"
+	test.Budgets = []types.TestBudget{
+		{Test: "D", DurationBudget: types.DurationBudget{MaxDuration: 30, StrictDuration: true}},
+	}
+
+	expected := map[string]float64{
+		types.OP_CREATE: 20, // module budget for the operation
+		types.OP_READ:   10, // module budget for all operations
+		types.OP_UPDATE: 10,
+		types.OP_DELETE: 30, // budget of the test
+	}
+	for CRUD, max := range expected {
+		if budget := config.GetBudget(test, CRUD); budget.MaxDuration != max {
+			t.Errorf("%v budget is %v, expected %v", CRUD, budget.MaxDuration, max)
+		}
+	}
+}
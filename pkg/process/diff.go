@@ -0,0 +1,278 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/cxpsemea/Cx1ClientGo"
+	"github.com/sirupsen/logrus"
+)
+
+type DiffOptions struct {
+	DurationThreshold float64 // percentage change in duration which is reported
+	DurationMinimum   float64 // changes of fewer seconds than this are ignored, to skip noise from quick tests
+}
+
+type DiffRun struct {
+	Report      string                  `json:"Report"`
+	Target      string                  `json:"TestTarget"`
+	Environment string                  `json:"Environment,omitempty"`
+	EndTime     string                  `json:"EndTime"`
+	Version     Cx1ClientGo.VersionInfo `json:"TargetVersions"`
+	Summary     Counter                 `json:"Summary"`
+}
+
+type DiffTest struct {
+	Key            string  `json:"Key"`
+	Name           string  `json:"Name"`
+	Source         string  `json:"Source"`
+	Test           string  `json:"Test"`
+	Before         string  `json:"Before,omitempty"`
+	After          string  `json:"After,omitempty"`
+	BeforeDuration float64 `json:"BeforeDuration,omitempty"`
+	AfterDuration  float64 `json:"AfterDuration,omitempty"`
+	Reason         string  `json:"Reason,omitempty"` // the failure or skip reason in the current run
+}
+
+// the differences between the results of two runs of the test suite
+type ReportDiff struct {
+	Base        DiffRun     `json:"Base"`
+	Current     DiffRun     `json:"Current"`
+	Options     DiffOptions `json:"Options"`
+	Regressions []DiffTest  `json:"Regressions"` // PASS -> FAIL
+	Fixed       []DiffTest  `json:"Fixed"`       // FAIL -> PASS
+	Changed     []DiffTest  `json:"Changed"`     // other result changes, eg: to or from SKIP
+	Added       []DiffTest  `json:"Added"`
+	Removed     []DiffTest  `json:"Removed"`
+	Slower      []DiffTest  `json:"Slower"`
+	Faster      []DiffTest  `json:"Faster"`
+}
+
+func makeDiffRun(reportName string, r *Report) DiffRun {
+	return DiffRun{
+		Report:      reportName,
+		Target:      r.Settings.Target,
+		Environment: r.Settings.Environment,
+		EndTime:     r.Settings.EndTime,
+		Version:     r.Settings.Version,
+		Summary:     r.Summary.Total,
+	}
+}
+
+func makeDiffTest(d *ReportTestDetails) DiffTest {
+	return DiffTest{Key: d.Key, Name: d.Name, Source: d.Source, Test: d.Test}
+}
+
+func (t DiffTest) DurationChange() float64 {
+	if t.BeforeDuration == 0 {
+		return 0
+	}
+	return (t.AfterDuration - t.BeforeDuration) / t.BeforeDuration * 100
+}
+
+// tests are matched on their Key, so reports from different versions of the configuration can be compared
+func DiffReports(baseName string, base *Report, currentName string, current *Report, options DiffOptions) ReportDiff {
+	diff := ReportDiff{
+		Base:    makeDiffRun(baseName, base),
+		Current: makeDiffRun(currentName, current),
+		Options: options,
+	}
+
+	baseTests := map[string]*ReportTestDetails{}
+	for id := range base.Details {
+		baseTests[base.Details[id].Key] = &base.Details[id]
+	}
+
+	seen := map[string]bool{}
+	for id := range current.Details {
+		c := &current.Details[id]
+		seen[c.Key] = true
+
+		test := makeDiffTest(c)
+		test.After = ResultString(c.ResultType)
+		test.AfterDuration = c.Duration
+		if c.ResultType != TST_PASS && len(c.FailOutputs) > 0 {
+			test.Reason = c.FailOutputs[0]
+		}
+
+		b, ok := baseTests[c.Key]
+		if !ok {
+			diff.Added = append(diff.Added, test)
+			continue
+		}
+
+		test.Before = ResultString(b.ResultType)
+		test.BeforeDuration = b.Duration
+
+		switch {
+		case b.ResultType == TST_PASS && c.ResultType == TST_FAIL:
+			diff.Regressions = append(diff.Regressions, test)
+		case b.ResultType == TST_FAIL && c.ResultType == TST_PASS:
+			diff.Fixed = append(diff.Fixed, test)
+		case b.ResultType != c.ResultType:
+			diff.Changed = append(diff.Changed, test)
+		case c.ResultType == TST_PASS || c.ResultType == TST_SLOW:
+			// durations of failed or skipped tests say little about the performance of the operation
+			change := test.DurationChange()
+			if math.Abs(test.AfterDuration-test.BeforeDuration) >= options.DurationMinimum && math.Abs(change) >= options.DurationThreshold {
+				if change > 0 {
+					diff.Slower = append(diff.Slower, test)
+				} else {
+					diff.Faster = append(diff.Faster, test)
+				}
+			}
+		}
+	}
+
+	for id := range base.Details {
+		b := &base.Details[id]
+		if !seen[b.Key] {
+			test := makeDiffTest(b)
+			test.Before = ResultString(b.ResultType)
+			test.BeforeDuration = b.Duration
+			diff.Removed = append(diff.Removed, test)
+		}
+	}
+
+	return diff
+}
+
+func OutputDiffConsole(diff *ReportDiff) {
+	fmt.Printf("%-10v %-40v %v\n", "", "Base", "Current")
+	fmt.Printf("%-10v %-40v %v\n", "Report", diff.Base.Report, diff.Current.Report)
+	fmt.Printf("%-10v %-40v %v\n", "Target", diff.Base.Target, diff.Current.Target)
+	fmt.Printf("%-10v %-40v %v\n", "Version", diff.Base.Version.String(), diff.Current.Version.String())
+	fmt.Printf("%-10v %-40v %v\n", "Results", diffSummary(&diff.Base.Summary), diffSummary(&diff.Current.Summary))
+
+	fmt.Println("")
+	fmt.Printf("Regressions: %d, fixed: %d, other changes: %d, added: %d, removed: %d, slower: %d, faster: %d\n", len(diff.Regressions), len(diff.Fixed), len(diff.Changed), len(diff.Added), len(diff.Removed), len(diff.Slower), len(diff.Faster))
+	for _, t := range diff.Regressions {
+		fmt.Printf("PASS -> FAIL: %v - %v: %v\n", t.Source, t.Test, t.Reason)
+	}
+	for _, t := range diff.Fixed {
+		fmt.Printf("FAIL -> PASS: %v - %v\n", t.Source, t.Test)
+	}
+	for _, t := range diff.Changed {
+		fmt.Printf("%v -> %v: %v - %v\n", t.Before, t.After, t.Source, t.Test)
+	}
+	for _, t := range diff.Added {
+		fmt.Printf("ADDED (%v): %v - %v\n", t.After, t.Source, t.Test)
+	}
+	for _, t := range diff.Removed {
+		fmt.Printf("REMOVED (%v): %v - %v\n", t.Before, t.Source, t.Test)
+	}
+	for _, t := range diff.Slower {
+		fmt.Printf("SLOWER %.2fs -> %.2fs (%+.0f%%): %v - %v\n", t.BeforeDuration, t.AfterDuration, t.DurationChange(), t.Source, t.Test)
+	}
+	for _, t := range diff.Faster {
+		fmt.Printf("FASTER %.2fs -> %.2fs (%+.0f%%): %v - %v\n", t.BeforeDuration, t.AfterDuration, t.DurationChange(), t.Source, t.Test)
+	}
+}
+
+func diffSummary(c *Counter) string {
+	return fmt.Sprintf("PASS %d, FAIL %d, SKIP %d", c.Pass, c.Fail, c.Skip)
+}
+
+type diffTable struct {
+	Title     string
+	Class     string
+	Tests     []DiffTest
+	Durations bool
+}
+
+var diffFuncs = template.FuncMap{
+	"summary": diffSummary,
+	"table": func(title, class string, tests []DiffTest, durations bool) diffTable {
+		return diffTable{title, class, tests, durations}
+	},
+}
+
+var diffTemplate = template.Must(template.New("diff").Funcs(diffFuncs).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Cx1 e2e result diff</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; border: 1px solid black; }
+th, td { border: 1px solid black; padding: 2px 4px; vertical-align: top; }
+.pass { color: green; }
+.fail { color: red; }
+</style>
+</head><body>
+<h2>Runs</h2>
+<table><tr><th></th><th>Base</th><th>Current</th></tr>
+<tr><th>Report</th><td>{{.Base.Report}}</td><td>{{.Current.Report}}</td></tr>
+<tr><th>Target</th><td>{{.Base.Target}}</td><td>{{.Current.Target}}</td></tr>
+<tr><th>Version</th><td>{{.Base.Version.String}}</td><td>{{.Current.Version.String}}</td></tr>
+<tr><th>Finished</th><td>{{.Base.EndTime}}</td><td>{{.Current.EndTime}}</td></tr>
+<tr><th>Results</th><td>{{summary .Base.Summary}}</td><td>{{summary .Current.Summary}}</td></tr>
+</table><br>
+{{template "table" (table "Regressions: PASS → FAIL" "fail" .Regressions false)}}
+{{template "table" (table "Fixed: FAIL → PASS" "pass" .Fixed false)}}
+{{template "table" (table "Other result changes" "" .Changed false)}}
+{{template "table" (table "Added tests" "" .Added false)}}
+{{template "table" (table "Removed tests" "" .Removed false)}}
+{{template "table" (table (printf "Slower by %.0f%% or more" .Options.DurationThreshold) "" .Slower true)}}
+{{template "table" (table (printf "Faster by %.0f%% or more" .Options.DurationThreshold) "" .Faster true)}}
+</body></html>
+{{define "table"}}<h2>{{if .Class}}<span class="{{.Class}}">{{.Title}}</span>{{else}}{{.Title}}{{end}} ({{len .Tests}})</h2>
+{{if .Tests}}<table><tr><th>Test Set</th><th>Test</th><th>Base</th><th>Current</th>{{if .Durations}}<th>Change</th>{{end}}</tr>
+{{$durations := .Durations}}{{range .Tests}}<tr><td>{{.Name}}<br>({{.Source}})</td><td>{{.Test}}</td>{{if $durations}}<td>{{printf "%.2fs" .BeforeDuration}}</td><td>{{printf "%.2fs" .AfterDuration}}</td><td>{{printf "%+.0f%%" .DurationChange}}</td>{{else}}<td>{{.Before}}</td><td>{{.After}}{{if .Reason}}: {{.Reason}}{{end}}</td>{{end}}</tr>
+{{end}}</table><br>
+{{end}}{{end}}`))
+
+func OutputDiffHTML(reportName string, diff *ReportDiff) error {
+	report, err := os.Create(reportName)
+	if err != nil {
+		return err
+	}
+
+	defer report.Close()
+
+	if err = diffTemplate.Execute(report, diff); err != nil {
+		return err
+	}
+
+	return report.Sync()
+}
+
+func OutputDiffJSON(reportName string, diff *ReportDiff) error {
+	report, err := os.Create(reportName)
+	if err != nil {
+		return err
+	}
+
+	defer report.Close()
+
+	json, err := json.Marshal(*diff)
+	if err != nil {
+		return err
+	}
+	_, err = report.Write(json)
+	if err != nil {
+		return err
+	}
+
+	return report.Sync()
+}
+
+// prints the diff to the console and writes it in the requested formats (html, json) to reportName
+func GenerateDiffReport(diff *ReportDiff, reportType, reportName string, logger *logrus.Logger) {
+	OutputDiffConsole(diff)
+
+	if strings.Contains(reportType, "html") {
+		filename := fmt.Sprintf("%v.html", reportName)
+		if err := OutputDiffHTML(filename, diff); err != nil {
+			logger.Errorf("Failed to write HTML diff report to %v: %s", filename, err)
+		}
+	}
+
+	if strings.Contains(reportType, "json") {
+		filename := fmt.Sprintf("%v.json", reportName)
+		if err := OutputDiffJSON(filename, diff); err != nil {
+			logger.Errorf("Failed to write JSON diff report to %v: %s", filename, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+)
+
+func (t *TestConfig) GetEnvironment(name string) (*Environment, error) {
+	for id := range t.Environments {
+		if t.Environments[id].Name == name {
+			return &t.Environments[id], nil
+		}
+	}
+	return nil, fmt.Errorf("environment %v is not defined in the test configuration %v", name, t.ConfigPath)
+}
+
+// ForEnvironment returns a copy of the configuration targeting the given environment
+// the tests are re-loaded from the config file since they hold state while running
+func (t *TestConfig) ForEnvironment(logger *logrus.Logger, env *Environment) (TestConfig, error) {
+	conf, err := LoadConfig(logger, t.ConfigPath)
+	if err != nil {
+		return conf, err
+	}
+
+	envConfig := *t
+	envConfig.Tests = conf.Tests
+	envConfig.Environment = env.Name
+	if env.Cx1URL != "" {
+		envConfig.Cx1URL = env.Cx1URL
+	}
+	if env.IAMURL != "" {
+		envConfig.IAMURL = env.IAMURL
+	}
+	if env.Tenant != "" {
+		envConfig.Tenant = env.Tenant
+	}
+	envConfig.ReportName = fmt.Sprintf("%v_%v", t.ReportName, env.Name)
+	if t.ArtifactsDir != "" {
+		envConfig.ArtifactsDir = filepath.Join(t.ArtifactsDir, env.Name)
+	}
+	if t.LogDir != "" {
+		envConfig.LogDir = filepath.Join(t.LogDir, env.Name)
+	}
+
+	// each environment gets the same test IDs
+	envConfig.InitTestIDs()
+
+	return envConfig, nil
+}
+
+func (e Environment) HasCredentials() bool {
+	return e.APIKeyEnv != "" || e.ClientIDEnv != "" || e.AccessTokenEnv != ""
+}
+
+func (e Environment) APIKey() string {
+	return getEnv(e.APIKeyEnv)
+}
+
+func (e Environment) ClientID() string {
+	return getEnv(e.ClientIDEnv)
+}
+
+func (e Environment) ClientSecret() string {
+	return getEnv(e.ClientSecretEnv)
+}
+
+func (e Environment) AccessToken() string {
+	return getEnv(e.AccessTokenEnv)
+}
+
+func getEnv(name string) string {
+	if name == "" {
+		return ""
+	}
+	return os.Getenv(name)
+}
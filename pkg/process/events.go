@@ -0,0 +1,110 @@
+package process
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	EVT_RUN_STARTED   = "run_started"
+	EVT_SET_STARTED   = "set_started"
+	EVT_TEST_STARTED  = "test_started"
+	EVT_TEST_RETRY    = "test_retry"
+	EVT_TEST_FINISHED = "test_finished"
+	EVT_CLEANUP       = "cleanup"
+	EVT_RUN_FINISHED  = "run_finished"
+)
+
+// a single line of the --events output, fields which do not apply to the event type are omitted
+type Event struct {
+	Time        time.Time `json:"Time"`
+	Event       string    `json:"Event"`
+	Environment string    `json:"Environment,omitempty"`
+	Thread      int       `json:"Thread,omitempty"`
+	Set         string    `json:"Set,omitempty"`
+	Source      string    `json:"Source,omitempty"`
+
+	TestID   uint     `json:"TestID,omitempty"`
+	Module   string   `json:"Module,omitempty"`
+	CRUD     string   `json:"CRUD,omitempty"`
+	Object   string   `json:"Object,omitempty"`
+	Negative bool     `json:"Negative,omitempty"`
+	Result   string   `json:"Result,omitempty"`
+	Reason   []string `json:"Reason,omitempty"`
+	Duration float64  `json:"Duration,omitempty"` // seconds
+	TraceID  string   `json:"TraceID,omitempty"`
+
+	Retry      uint `json:"Retry,omitempty"` // retry number, starting from 1
+	MaxRetries uint `json:"MaxRetries,omitempty"`
+	RetryDelay uint `json:"RetryDelay,omitempty"` // seconds
+
+	Action  string `json:"Action,omitempty"` // cleanup action, eg: delete
+	Success *bool  `json:"Success,omitempty"`
+
+	Config  string   `json:"Config,omitempty"`
+	Tests   int      `json:"Tests,omitempty"`
+	Threads int      `json:"Threads,omitempty"`
+	Summary *Counter `json:"Summary,omitempty"`
+}
+
+// EventWriter writes the events of a run as newline-delimited JSON, one event per line as it happens
+type EventWriter struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+	file    *os.File
+}
+
+// NewEventWriter writes the events to a file, or to stdout when the filename is "-"
+func NewEventWriter(filename string) (*EventWriter, error) {
+	if filename == "-" {
+		return newEventWriter(os.Stdout, nil), nil
+	}
+	file, err := os.Create(filename)
+	if err != nil {
+		return nil, err
+	}
+	return newEventWriter(file, file), nil
+}
+
+func newEventWriter(w io.Writer, file *os.File) *EventWriter {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return &EventWriter{encoder: encoder, file: file}
+}
+
+func (e *EventWriter) Emit(Config *TestConfig, event Event) {
+	if e == nil {
+		return
+	}
+	event.Time = time.Now()
+	event.Environment = Config.Environment
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	_ = e.encoder.Encode(event) // a consumer going away should not stop the tests
+}
+
+func (e *EventWriter) Close() error {
+	if e == nil || e.file == nil {
+		return nil
+	}
+	return e.file.Close()
+}
+
+// an event with the fields identifying a test
+func testEvent(eventType, testName, CRUD string, test TestRunner) Event {
+	return Event{
+		Event:    eventType,
+		Thread:   test.GetCurrentThread(),
+		Set:      testName,
+		Source:   test.GetSource(),
+		TestID:   test.GetID(),
+		Module:   test.GetModule(),
+		CRUD:     CRUD,
+		Object:   test.String(),
+		Negative: test.IsNegative(),
+	}
+}
@@ -0,0 +1,73 @@
+package process
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	EXIT_MODE_COUNT     = "count"     // the number of failed tests, up to EXIT_MAX_COUNT
+	EXIT_MODE_BINARY    = "binary"    // EXIT_TESTS_FAILED if any test failed
+	EXIT_MODE_THRESHOLD = "threshold" // EXIT_TESTS_FAILED if more tests failed than allowed
+
+	EXIT_OK           uint = 0
+	EXIT_TESTS_FAILED uint = 1
+	EXIT_MAX_COUNT    uint = 100 // 100 or more failures, so that the count does not wrap around at 256
+	EXIT_CONFIG_ERROR uint = 101 // invalid flags or configuration, the tests were not run
+	EXIT_AUTH_ERROR   uint = 102 // failed to connect or authenticate to Cx1, the tests were not run
+)
+
+var ExitModes = []string{EXIT_MODE_COUNT, EXIT_MODE_BINARY, EXIT_MODE_THRESHOLD}
+
+// ExitPolicy turns the results of a run into the exit code of the process
+type ExitPolicy struct {
+	Mode               string
+	FailOnSkip         bool // skipped tests count as failures
+	MaxFailuresAllowed uint // with the threshold mode
+}
+
+func (p ExitPolicy) Validate() error {
+	switch p.Mode {
+	case "", EXIT_MODE_COUNT, EXIT_MODE_BINARY, EXIT_MODE_THRESHOLD:
+	default:
+		return fmt.Errorf("invalid exit code mode %v, options are: %v", p.Mode, strings.Join(ExitModes, ", "))
+	}
+	if p.MaxFailuresAllowed > 0 && p.Mode != EXIT_MODE_THRESHOLD {
+		return fmt.Errorf("max-failures-allowed requires the %v exit code mode", EXIT_MODE_THRESHOLD)
+	}
+	return nil
+}
+
+// the number of tests treated as failed by this policy
+func (p ExitPolicy) Failures(summary Counter) uint {
+	failures := summary.Fail
+	if p.FailOnSkip {
+		failures += summary.Skip
+	}
+	return failures
+}
+
+func (p ExitPolicy) Code(summary Counter) uint {
+	failures := p.Failures(summary)
+	switch p.Mode {
+	case EXIT_MODE_BINARY:
+		if failures > 0 {
+			return EXIT_TESTS_FAILED
+		}
+	case EXIT_MODE_THRESHOLD:
+		if failures > p.MaxFailuresAllowed {
+			return EXIT_TESTS_FAILED
+		}
+	default:
+		return ExitCount(failures)
+	}
+	return EXIT_OK
+}
+
+// ExitCount limits a count to EXIT_MAX_COUNT, for commands which exit with the number of failures
+func ExitCount(count uint) uint {
+	if count > EXIT_MAX_COUNT {
+		return EXIT_MAX_COUNT
+	}
+	return count
+}
@@ -0,0 +1,300 @@
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HAR 1.2, see http://www.softwareishard.com/blog/har-12-spec/
+type HARFile struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Comment string     `json:"comment,omitempty"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"` // milliseconds
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+	Comment         string      `json:"comment,omitempty"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int64          `json:"bodySize"`
+}
+
+type HARContent struct {
+	Size     int64  `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Comment  string `json:"comment,omitempty"`
+}
+
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+const (
+	HAR_NONE     = ""
+	HAR_FAILURES = "failures"
+	HAR_ALL      = "all"
+
+	harMaxBodySize = 1024 * 1024
+	harRedacted    = "[REDACTED]"
+)
+
+var (
+	harRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie"}
+	harRedactFields  = []string{"access_token", "refresh_token", "id_token", "client_secret", "clientSecret", "secret", "password", "apikey", "apiKey", "api_key", "token", "client_assertion"}
+	harRedactJSON    = regexp.MustCompile(`("(?:` + strings.Join(harRedactFields, "|") + `)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+)
+
+func redactHeaders(header http.Header) []HARNameValue {
+	list := []HARNameValue{}
+	for name, values := range header {
+		for _, value := range values {
+			for _, redact := range harRedactHeaders {
+				if strings.EqualFold(name, redact) {
+					value = harRedacted
+				}
+			}
+			list = append(list, HARNameValue{Name: name, Value: value})
+		}
+	}
+	return list
+}
+
+func redactValues(values url.Values) url.Values {
+	redacted := url.Values{}
+	for name, list := range values {
+		for _, value := range list {
+			for _, field := range harRedactFields {
+				if strings.EqualFold(name, field) {
+					value = harRedacted
+				}
+			}
+			redacted.Add(name, value)
+		}
+	}
+	return redacted
+}
+
+// removes credentials and tokens from form-encoded or JSON bodies
+func redactBody(mimeType, body string) string {
+	if strings.Contains(mimeType, "x-www-form-urlencoded") {
+		if values, err := url.ParseQuery(body); err == nil {
+			return redactValues(values).Encode()
+		}
+	}
+	return harRedactJSON.ReplaceAllString(body, `$1"`+harRedacted+`"`)
+}
+
+func isTextContent(mimeType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+	return mediaType == "" || strings.HasPrefix(mediaType, "text/") || strings.Contains(mediaType, "json") || strings.Contains(mediaType, "xml") || strings.Contains(mediaType, "x-www-form-urlencoded")
+}
+
+func harURL(u *url.URL) (string, []HARNameValue) {
+	redacted := *u
+	query := redactValues(u.Query())
+	redacted.RawQuery = query.Encode()
+	redacted.User = nil
+
+	list := []HARNameValue{}
+	for name, values := range query {
+		for _, value := range values {
+			list = append(list, HARNameValue{Name: name, Value: value})
+		}
+	}
+	return redacted.String(), list
+}
+
+// reads the body for the HAR entry and returns a replacement reader with the same content
+func harReadBody(body io.ReadCloser) (string, io.ReadCloser, bool) {
+	if body == nil || body == http.NoBody {
+		return "", body, false
+	}
+	data, err := io.ReadAll(io.LimitReader(body, harMaxBodySize+1))
+	truncated := len(data) > harMaxBodySize
+	replacement := &readCloser{io.MultiReader(bytes.NewReader(data), body), body}
+	if err != nil || truncated {
+		return string(data[:min(len(data), harMaxBodySize)]), replacement, true
+	}
+	return string(data), replacement, false
+}
+
+func newHARRequest(req *http.Request) HARRequest {
+	u, query := harURL(req.URL)
+	request := HARRequest{
+		Method:      req.Method,
+		URL:         u,
+		HTTPVersion: req.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     redactHeaders(req.Header),
+		QueryString: query,
+		HeadersSize: -1,
+		BodySize:    max(req.ContentLength, 0),
+	}
+
+	if req.Body != nil && req.Body != http.NoBody {
+		var text string
+		mimeType := req.Header.Get("Content-Type")
+		if req.GetBody != nil {
+			if body, err := req.GetBody(); err == nil {
+				text, _, _ = harReadBody(body)
+				body.Close()
+			}
+		} else {
+			text, req.Body, _ = harReadBody(req.Body)
+		}
+		if isTextContent(mimeType) {
+			request.PostData = &HARPostData{MimeType: mimeType, Text: redactBody(mimeType, text)}
+		} else {
+			request.PostData = &HARPostData{MimeType: mimeType}
+		}
+	}
+
+	return request
+}
+
+func newHARResponse(resp *http.Response) (HARResponse, string) {
+	mimeType := resp.Header.Get("Content-Type")
+	response := HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprintf("%d", resp.StatusCode))),
+		HTTPVersion: resp.Proto,
+		Cookies:     []HARNameValue{},
+		Headers:     redactHeaders(resp.Header),
+		Content:     HARContent{Size: resp.ContentLength, MimeType: mimeType},
+		HeadersSize: -1,
+		BodySize:    resp.ContentLength,
+	}
+
+	if !isTextContent(mimeType) {
+		response.Content.Comment = "binary content not captured"
+		return response, ""
+	}
+
+	text, body, truncated := harReadBody(resp.Body)
+	resp.Body = body
+	response.Content.Text = redactBody(mimeType, text)
+	if truncated {
+		response.Content.Comment = fmt.Sprintf("content truncated to %d bytes", harMaxBodySize)
+	}
+	return response, text
+}
+
+// a file name for the test derived from its key, so that artifacts of the same test keep their name between runs
+func (d *ReportTestDetails) FileName() string {
+	name := unsafeFileChars.ReplaceAllString(d.Key, "_")
+	if len(name) > 120 {
+		name = name[len(name)-120:]
+	}
+	hash := fnv.New32a()
+	hash.Write([]byte(d.Key))
+	return fmt.Sprintf("%v_%08x", strings.Trim(name, "_"), hash.Sum32())
+}
+
+// WriteHARFiles writes the captured HTTP exchanges of failed tests (or all tests) to <report name>_har/ and links them from the report
+func WriteHARFiles(reportData *Report, Config *TestConfig, logger *logrus.Logger) {
+	if Config.HAR == HAR_NONE {
+		return
+	}
+
+	dir := fmt.Sprintf("%v_har", Config.ReportName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		logger.Errorf("Failed to create HAR directory %v: %s", dir, err)
+		return
+	}
+
+	for id := range reportData.Details {
+		d := &reportData.Details[id]
+		if Config.HAR == HAR_FAILURES && d.ResultType != TST_FAIL {
+			continue
+		}
+
+		har := HARFile{Log: HARLog{
+			Version: "1.2",
+			Creator: HARCreator{Name: "cx1e2e", Version: "1.0"},
+			Comment: fmt.Sprintf("%v - %v: %v", d.Name, d.Test, d.Result),
+			Entries: []HAREntry{},
+		}}
+		for _, c := range d.Calls {
+			if c.Exchange != nil {
+				har.Log.Entries = append(har.Log.Entries, *c.Exchange)
+			}
+		}
+		if len(har.Log.Entries) == 0 {
+			continue
+		}
+
+		filename := d.FileName() + ".har"
+		data, err := json.MarshalIndent(har, "", "  ")
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, filename), data, 0600)
+		}
+		if err != nil {
+			logger.Errorf("Failed to write HAR file for test %v: %s", d.Key, err)
+			continue
+		}
+		d.HAR = filepath.ToSlash(filepath.Join(filepath.Base(dir), filename))
+	}
+}
@@ -0,0 +1,173 @@
+package process
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// one run of the test suite, stored as a line in <history dir>/<environment>.jsonl
+type HistoryEntry struct {
+	Environment string    `json:"Environment"`
+	Version     string    `json:"Version"` // Cx1 version string of the environment at the time of the run
+	Time        time.Time `json:"Time"`
+	Report      Report    `json:"Report"`
+}
+
+type HistoryFilter struct {
+	Environment string
+	Version     string // only runs where the version contains this string
+	Limit       int    // only the last N runs per environment
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// the environment name, or the host and tenant when not running against a named environment
+func historyEnvironment(Config *TestConfig) string {
+	if Config.Environment != "" {
+		return Config.Environment
+	}
+	host := Config.Cx1URL
+	if u, err := url.Parse(Config.Cx1URL); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return fmt.Sprintf("%v_%v", host, Config.Tenant)
+}
+
+func historyFile(historyDir, environment string) string {
+	return filepath.Join(historyDir, unsafeFileChars.ReplaceAllString(environment, "_")+".jsonl")
+}
+
+func AppendHistory(historyDir string, Config *TestConfig, reportData *Report, endTime time.Time) error {
+	if err := os.MkdirAll(historyDir, 0755); err != nil {
+		return err
+	}
+
+	entry := HistoryEntry{
+		Environment: historyEnvironment(Config),
+		Version:     reportData.Settings.Version.String(),
+		Time:        endTime,
+		Report:      *reportData,
+	}
+
+	// the individual API calls would make the history grow quickly, the report itself has them
+	entry.Report.Details = slices.Clone(reportData.Details)
+	for id := range entry.Report.Details {
+		entry.Report.Details[id].Calls = nil
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(historyFile(historyDir, entry.Environment), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if _, err = file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// LoadHistory reads the stored runs, ordered by time
+func LoadHistory(logger *logrus.Logger, historyDir string, filter HistoryFilter) ([]HistoryEntry, error) {
+	files, err := filepath.Glob(filepath.Join(historyDir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	if filter.Environment != "" {
+		files = []string{historyFile(historyDir, filter.Environment)}
+	}
+
+	entries := []HistoryEntry{}
+	for _, filename := range files {
+		fileEntries, err := loadHistoryFile(logger, filename, filter)
+		if err != nil {
+			return entries, err
+		}
+		if filter.Limit > 0 && len(fileEntries) > filter.Limit {
+			fileEntries = fileEntries[len(fileEntries)-filter.Limit:]
+		}
+		entries = append(entries, fileEntries...)
+	}
+
+	slices.SortStableFunc(entries, func(a, b HistoryEntry) int {
+		return a.Time.Compare(b.Time)
+	})
+
+	return entries, nil
+}
+
+func loadHistoryFile(logger *logrus.Logger, filename string, filter HistoryFilter) ([]HistoryEntry, error) {
+	entries := []HistoryEntry{}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return entries, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 256*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry HistoryEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			// an interrupted run can leave a partial line, which should not hide the rest of the history
+			logger.Warnf("Skipping invalid history entry %v line %d: %s", filename, line, err)
+			continue
+		}
+
+		if filter.Version != "" && !strings.Contains(entry.Version, filter.Version) {
+			continue
+		}
+
+		entry.Report.restoreResultTypes()
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("failed to read history file %v: %s", filename, err)
+	}
+
+	slices.SortStableFunc(entries, func(a, b HistoryEntry) int {
+		return a.Time.Compare(b.Time)
+	})
+	return entries, nil
+}
+
+// slow tests passed, so they count towards the pass rate
+func (e *HistoryEntry) PassRate() float64 {
+	total := e.Report.Summary.Total.Count()
+	if total == 0 {
+		return 0
+	}
+	return float64(e.Report.Summary.Total.Pass+e.Report.Summary.Total.Slow) / float64(total) * 100
+}
+
+func OutputHistoryConsole(entries []HistoryEntry) {
+	fmt.Printf("%-25v %-30v %-40v %6v %6v %6v %6v %8v\n", "Time", "Environment", "Version", "Pass", "Slow", "Fail", "Skip", "Rate")
+	for id := range entries {
+		e := &entries[id]
+		total := &e.Report.Summary.Total
+		fmt.Printf("%-25v %-30v %-40v %6d %6d %6d %6d %7.1f%%\n", e.Time.Format("2006-01-02 15:04:05"), e.Environment, e.Version, total.Pass, total.Slow, total.Fail, total.Skip, e.PassRate())
+	}
+}
@@ -0,0 +1,243 @@
+package process
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+)
+
+// operations which take long enough that their duration is worth tracking between runs
+var historyDurationModules = []string{types.MOD_SCAN, types.MOD_IMPORT, types.MOD_REPORT}
+
+type HistoryFlips struct {
+	Environment string
+	Key         string
+	Name        string
+	Test        string
+	Runs        int
+	Flips       int
+	Last        string
+}
+
+type HistoryDurations struct {
+	Environment string
+	Key         string
+	Name        string
+	Test        string
+	Durations   []float64
+}
+
+func (d *HistoryDurations) Stats() (min, avg, max float64) {
+	for id, v := range d.Durations {
+		if id == 0 || v < min {
+			min = v
+		}
+		if v > max {
+			max = v
+		}
+		avg += v
+	}
+	if len(d.Durations) > 0 {
+		avg /= float64(len(d.Durations))
+	}
+	return
+}
+
+func historyEnvironments(entries []HistoryEntry) []string {
+	environments := []string{}
+	for _, e := range entries {
+		if !slices.Contains(environments, e.Environment) {
+			environments = append(environments, e.Environment)
+		}
+	}
+	return environments
+}
+
+// counts how often each test changed result between consecutive runs against the same environment
+func historyFlips(entries []HistoryEntry) []HistoryFlips {
+	flips := map[string]*HistoryFlips{}
+	keys := []string{}
+
+	for _, e := range entries {
+		for _, d := range e.Report.Details {
+			id := e.Environment + "|" + d.Key
+			f, ok := flips[id]
+			result := ResultString(d.ResultType)
+			if !ok {
+				f = &HistoryFlips{Environment: e.Environment, Key: d.Key, Name: d.Name, Test: d.Test}
+				flips[id] = f
+				keys = append(keys, id)
+			} else if f.Last != result {
+				f.Flips++
+			}
+			f.Runs++
+			f.Last = result
+		}
+	}
+
+	list := []HistoryFlips{}
+	for _, id := range keys {
+		if flips[id].Flips > 0 {
+			list = append(list, *flips[id])
+		}
+	}
+	slices.SortStableFunc(list, func(a, b HistoryFlips) int {
+		return b.Flips - a.Flips
+	})
+	return list
+}
+
+// collects the durations of passing scan, import and report tests
+func historyDurations(entries []HistoryEntry) []HistoryDurations {
+	durations := map[string]*HistoryDurations{}
+	keys := []string{}
+
+	for _, e := range entries {
+		for _, d := range e.Report.Details {
+			if (d.ResultType != TST_PASS && d.ResultType != TST_SLOW) || !slices.Contains(historyDurationModules, d.Module) {
+				continue
+			}
+			id := e.Environment + "|" + d.Key
+			h, ok := durations[id]
+			if !ok {
+				h = &HistoryDurations{Environment: e.Environment, Key: d.Key, Name: d.Name, Test: d.Test}
+				durations[id] = h
+				keys = append(keys, id)
+			}
+			h.Durations = append(h.Durations, d.Duration)
+		}
+	}
+
+	list := []HistoryDurations{}
+	for _, id := range keys {
+		list = append(list, *durations[id])
+	}
+	return list
+}
+
+// the points of a simple line chart, values are scaled between 0 and maxValue (or the largest value if maxValue is 0)
+func svgPoints(values []float64, width, height int, maxValue float64) string {
+	if maxValue == 0 {
+		maxValue = slices.Max(append([]float64{0}, values...))
+	}
+	if maxValue == 0 {
+		maxValue = 1
+	}
+
+	points := []string{}
+	for id, v := range values {
+		x := 0.0
+		if len(values) > 1 {
+			x = float64(id) * float64(width) / float64(len(values)-1)
+		}
+		y := float64(height) - v/maxValue*float64(height)
+		points = append(points, fmt.Sprintf("%.1f,%.1f", x, y))
+	}
+
+	return strings.Join(points, " ")
+}
+
+type historyChart struct {
+	Points string
+	Width  int
+	Height int
+}
+
+type historyPassRate struct {
+	Environment string
+	Runs        int
+	Chart       historyChart
+}
+
+type historyDurationRow struct {
+	HistoryDurations
+	Min, Avg, Max, Last float64
+	Chart               historyChart
+}
+
+type historyReportData struct {
+	PassRates []historyPassRate
+	Runs      []*HistoryEntry // newest first
+	Flips     []HistoryFlips
+	Modules   string
+	Durations []historyDurationRow
+}
+
+func prepareHistoryReportData(entries []HistoryEntry) historyReportData {
+	data := historyReportData{
+		Flips:   historyFlips(entries),
+		Modules: strings.Join(historyDurationModules, ", "),
+	}
+
+	for _, env := range historyEnvironments(entries) {
+		rates := []float64{}
+		for id := range entries {
+			if entries[id].Environment == env {
+				rates = append(rates, entries[id].PassRate())
+			}
+		}
+		data.PassRates = append(data.PassRates, historyPassRate{env, len(rates), historyChart{svgPoints(rates, 600, 150, 100), 600, 150}})
+	}
+
+	for id := len(entries) - 1; id >= 0; id-- {
+		data.Runs = append(data.Runs, &entries[id])
+	}
+
+	for _, d := range historyDurations(entries) {
+		row := historyDurationRow{HistoryDurations: d, Last: d.Durations[len(d.Durations)-1]}
+		row.Min, row.Avg, row.Max = d.Stats()
+		row.Chart = historyChart{svgPoints(d.Durations, 200, 30, 0), 200, 30}
+		data.Durations = append(data.Durations, row)
+	}
+	return data
+}
+
+var historyTemplate = template.Must(template.New("history").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Cx1 e2e history</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; border: 1px solid black; }
+th, td { border: 1px solid black; padding: 2px 4px; vertical-align: top; }
+svg { border: 1px solid #ccc; overflow: visible; }
+</style>
+</head><body>
+<h2>Pass rate</h2>
+{{range .PassRates}}<h3>{{.Environment}} ({{.Runs}} runs)</h3>
+{{template "chart" .Chart}}<br>
+{{end}}
+<h2>Runs</h2>
+<table><tr><th>Time</th><th>Environment</th><th>Version</th><th>Pass</th><th title="Passed, but exceeded the maximum duration">Slow</th><th>Fail</th><th>Skip</th><th>Pass rate</th></tr>
+{{range .Runs}}<tr><td>{{.Time.Format "2006-01-02 15:04:05"}}</td><td>{{.Environment}}</td><td>{{.Version}}</td>{{with .Report.Summary.Total}}<td>{{.Pass}}</td><td>{{.Slow}}</td><td>{{.Fail}}</td><td>{{.Skip}}</td>{{end}}<td>{{printf "%.1f%%" .PassRate}}</td></tr>
+{{end}}</table><br>
+<h2>Flaky tests</h2>
+{{if .Flips}}<table><tr><th>Environment</th><th>Test Set</th><th>Test</th><th>Runs</th><th>Result changes</th><th>Last result</th></tr>
+{{range .Flips}}<tr><td>{{.Environment}}</td><td>{{.Name}}</td><td>{{.Test}}</td><td>{{.Runs}}</td><td>{{.Flips}}</td><td>{{.Last}}</td></tr>
+{{end}}</table><br>
+{{else}}No test changed result between runs.<br>
+{{end}}
+<h2>Durations</h2>
+Durations of passing {{.Modules}} tests.<br>
+<table><tr><th>Environment</th><th>Test Set</th><th>Test</th><th>Runs</th><th>Min</th><th>Avg</th><th>Max</th><th>Last</th><th>Trend</th></tr>
+{{range .Durations}}<tr><td>{{.Environment}}</td><td>{{.Name}}</td><td>{{.Test}}</td><td>{{len .Durations}}</td><td>{{printf "%.1fs" .Min}}</td><td>{{printf "%.1fs" .Avg}}</td><td>{{printf "%.1fs" .Max}}</td><td>{{printf "%.1fs" .Last}}</td><td>{{template "chart" .Chart}}</td></tr>
+{{end}}</table>
+</body></html>
+{{define "chart"}}<svg width="{{.Width}}" height="{{.Height}}"><polyline fill="none" stroke="steelblue" stroke-width="2" points="{{.Points}}"/></svg>{{end}}`))
+
+func OutputHistoryHTML(reportName string, entries []HistoryEntry) error {
+	report, err := os.Create(reportName)
+	if err != nil {
+		return err
+	}
+
+	defer report.Close()
+
+	if err = historyTemplate.Execute(report, prepareHistoryReportData(entries)); err != nil {
+		return err
+	}
+
+	return report.Sync()
+}
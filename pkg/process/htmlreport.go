@@ -0,0 +1,172 @@
+package process
+
+import (
+	"html/template"
+	"os"
+	"slices"
+	"strings"
+)
+
+type htmlReportData struct {
+	*Report
+	Areas   []ReportArea
+	Modules []string
+	Sets    []string
+	Threads []int
+
+	Endpoints   []EndpointStats
+	FailedCalls []FailedAPICall
+}
+
+type htmlReportCell struct {
+	Count uint
+	Class string
+}
+
+var reportFuncs = template.FuncMap{
+	"result": ResultString,
+	"join":   strings.Join,
+	"lower":  strings.ToLower,
+	"ops": func(c *CounterSet) []Counter {
+		return []Counter{c.Create, c.Read, c.Update, c.Delete}
+	},
+	"cell": func(count uint, class string) htmlReportCell {
+		return htmlReportCell{count, class}
+	},
+}
+
+// the report is self-contained (no external scripts or styles) so that it can be archived and opened from anywhere
+// all values are escaped by html/template, failure reasons can contain arbitrary server responses
+var reportTemplate = template.Must(template.New("report").Funcs(reportFuncs).Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Settings.Target}} test - {{.Settings.EndTime}}</title>
+<style>
+body { font-family: sans-serif; font-size: 14px; }
+table { border-collapse: collapse; border: 1px solid black; }
+th, td { border: 1px solid black; padding: 2px 4px; vertical-align: top; }
+td.count { text-align: center; }
+.pass { color: green; }
+.fail { color: red; }
+.skip { color: orange; }
+.slow, .warn { color: darkorange; }
+th.sortable { cursor: pointer; text-decoration: underline; }
+pre { white-space: pre-wrap; margin: 4px 0; }
+#filters select { margin-right: 12px; }
+</style>
+</head><body>
+<h2>Settings</h2>
+{{with .Settings}}{{if .Environment}}Environment {{.Environment}}<br>{{end}}
+Running end to end tests against {{.Target}} (version: {{.Version.String}})<br>
+Authenticated using {{.Auth}}<br>
+Test set defined in configuration {{.Config}}<br>
+Test Execution took {{.Duration}}, from {{.StartTime}} until {{.EndTime}}.<br>
+Tests executed using {{.Threads}} threads.<br>
+{{if .E2ESuffix}}Default object name suffix %E2E_RUN_SUFFIX% environment variable is set to {{.E2ESuffix}}. Objects created by cx1e2e will use this suffix in the name.<br>
+{{else}}Default object name suffix %E2E_RUN_SUFFIX% environment variable is blank. Objects created by cx1e2e will use default names.<br>
+{{end}}{{end}}
+<h2>Summary</h2>
+<p>Test status:<br>FAIL: {{.Summary.Total.Fail}}<br>SKIP: {{.Summary.Total.Skip}}<br>PASS: {{.Summary.Total.Pass}}{{if .Summary.Total.Flaky}} (of which {{.Summary.Total.Flaky}} passed after being retried){{end}}<br>{{if .Summary.Total.Slow}}SLOW: {{.Summary.Total.Slow}} (passed, but exceeded the maximum duration){{end}}</p>
+<table><tr><th rowspan=2>Area</th><th colspan=5>Create</th><th colspan=5>Read</th><th colspan=5>Update</th><th colspan=5>Delete</th></tr>
+<tr><th>Pass</th><th>Fail</th><th>Skip</th><th title="Passed after being retried">Flaky</th><th title="Passed, but exceeded the maximum duration">Slow</th><th>Pass</th><th>Fail</th><th>Skip</th><th title="Passed after being retried">Flaky</th><th title="Passed, but exceeded the maximum duration">Slow</th><th>Pass</th><th>Fail</th><th>Skip</th><th title="Passed after being retried">Flaky</th><th title="Passed, but exceeded the maximum duration">Slow</th><th>Pass</th><th>Fail</th><th>Skip</th><th title="Passed after being retried">Flaky</th><th title="Passed, but exceeded the maximum duration">Slow</th></tr>
+{{range .Areas}}<tr><td>{{.Label}}</td>{{range ops .Count}}{{template "cell" (cell .Pass "pass")}}{{template "cell" (cell .Fail "fail")}}{{template "cell" (cell .Skip "skip")}}{{template "cell" (cell .Flaky "pass")}}{{template "cell" (cell .Slow "slow")}}{{end}}</tr>
+{{end}}</table><br>
+{{if .SLA}}<h2>Performance budgets</h2>
+<p>{{.SLAViolations}} of {{len .SLA}} tests with a duration budget exceeded their warning or maximum duration.</p>
+<table><tr><th>Test Set</th><th>Test</th><th>Duration (sec)</th><th>Warn (sec)</th><th>Max (sec)</th><th>Usage</th><th>Status</th></tr>
+{{range .SLA}}<tr><td>{{.Name}}</td><td>{{.Test}}</td><td>{{printf "%.2f" .Duration}}</td><td>{{if .Budget.WarnDuration}}{{.Budget.WarnDuration}}{{end}}</td><td>{{if .Budget.MaxDuration}}{{.Budget.MaxDuration}}{{if .Budget.StrictDuration}} (strict){{end}}{{end}}</td><td>{{printf "%.0f%%" .Usage}}</td><td class="{{lower .Status}}">{{.Status}}</td></tr>
+{{end}}</table><br>
+{{end}}{{if .Endpoints}}<h2>Slowest API endpoints</h2>
+<table><tr><th>Endpoint</th><th>Calls</th><th>Errors</th><th>Avg (sec)</th><th>Max (sec)</th></tr>
+{{range .Endpoints}}<tr><td>{{.Method}} {{.Path}}</td><td>{{.Calls}}</td><td>{{if .Errors}}<span class="fail">{{.Errors}}</span>{{end}}</td><td>{{printf "%.3f" .AvgLatency}}</td><td>{{printf "%.3f" .MaxLatency}}</td></tr>
+{{end}}</table><br>
+{{end}}{{if .FailedCalls}}<h2>Failed API calls</h2>
+<table><tr><th>Test Set</th><th>Test</th><th>Request</th><th>Status</th><th>Response</th></tr>
+{{range .FailedCalls}}<tr><td>{{.Test.Name}}</td><td>{{.Test.Test}}</td><td>{{.Call.Method}} {{.Call.Path}}</td><td class="fail">{{if .Call.Error}}{{.Call.Error}}{{else}}{{.Call.Status}}{{end}}</td><td><pre>{{.Call.Snippet}}</pre></td></tr>
+{{end}}</table><br>
+{{end}}<h2>Details</h2>
+<div id="filters">
+Result: <select id="filter-result"><option value="">all</option><option>PASS</option><option>FAIL</option><option>SKIP</option><option>SLOW</option></select>
+Module: <select id="filter-module"><option value="">all</option>{{range .Modules}}<option>{{.}}</option>{{end}}</select>
+Test Set: <select id="filter-set"><option value="">all</option>{{range .Sets}}<option>{{.}}</option>{{end}}</select>
+Thread: <select id="filter-thread"><option value="">all</option>{{range .Threads}}<option>{{.}}</option>{{end}}</select>
+<span id="filter-count"></span>
+</div><br>
+<table id="details"><thead><tr><th>Test Set</th><th>Test</th><th>Thread</th><th class="sortable" id="sort-duration">Duration (sec)</th><th>Result</th></tr></thead><tbody>
+{{range .Details}}{{$result := result .ResultType}}<tr data-result="{{$result}}" data-module="{{.Module}}" data-set="{{.Name}}" data-thread="{{.Thread}}" data-duration="{{.Duration}}">
+<td>{{.Name}}<br>({{.Source}})</td><td>{{.Test}}</td><td>{{if .Thread}}T{{.Thread}}{{end}}</td><td>{{printf "%.2f" .Duration}}</td>
+<td class="{{lower $result}}">{{if eq .ResultType 1}}{{.Result}}{{if gt .Attempts 1}} ({{.Attempts}} attempts){{end}}{{else}}<details><summary>{{.Result}}</summary><pre>{{join .FailOutputs "\n"}}</pre>{{range .Calls}}{{if .IsError}}<pre>{{.String}}: {{.Snippet}}</pre>{{end}}{{end}}{{if .TraceID}}<pre>Trace ID: {{.TraceID}}</pre>{{end}}</details>{{end}}{{if .LogExcerpt}} <a href="{{.LogExcerpt}}">log</a>{{end}}{{if .HAR}} <a href="{{.HAR}}">HAR</a>{{end}}{{range .Artifacts}} <a href="{{.Path}}">{{.Name}}</a>{{end}}</td></tr>
+{{end}}</tbody></table>
+<script>
+(function() {
+	var rows = Array.prototype.slice.call(document.querySelectorAll("#details tbody tr"));
+	var filters = { result: "filter-result", module: "filter-module", set: "filter-set", thread: "filter-thread" };
+	function applyFilters() {
+		var shown = 0;
+		rows.forEach(function(row) {
+			var visible = Object.keys(filters).every(function(key) {
+				var value = document.getElementById(filters[key]).value;
+				return value === "" || row.dataset[key] === value;
+			});
+			row.style.display = visible ? "" : "none";
+			if (visible) { shown++; }
+		});
+		document.getElementById("filter-count").textContent = shown + " of " + rows.length + " tests shown";
+	}
+	Object.keys(filters).forEach(function(key) {
+		document.getElementById(filters[key]).addEventListener("change", applyFilters);
+	});
+	var ascending = false;
+	document.getElementById("sort-duration").addEventListener("click", function() {
+		ascending = !ascending;
+		rows.sort(function(a, b) {
+			var diff = parseFloat(a.dataset.duration) - parseFloat(b.dataset.duration);
+			return ascending ? diff : -diff;
+		});
+		var body = document.querySelector("#details tbody");
+		rows.forEach(function(row) { body.appendChild(row); });
+	});
+	applyFilters();
+})();
+</script>
+</body></html>
+{{define "cell"}}{{if .Count}}<td class="count {{.Class}}">{{.Count}}</td>{{else}}<td>&nbsp;</td>{{end}}{{end}}`))
+
+func prepareHTMLReportData(reportData *Report) htmlReportData {
+	data := htmlReportData{
+		Report: reportData,
+		Areas:  reportData.Summary.Areas(),
+
+		Endpoints:   reportData.SlowestEndpoints(20),
+		FailedCalls: reportData.FailedAPICalls(),
+	}
+
+	for _, d := range reportData.Details {
+		if d.Module != "" && !slices.Contains(data.Modules, d.Module) {
+			data.Modules = append(data.Modules, d.Module)
+		}
+		if !slices.Contains(data.Sets, d.Name) {
+			data.Sets = append(data.Sets, d.Name)
+		}
+		if d.Thread != 0 && !slices.Contains(data.Threads, d.Thread) {
+			data.Threads = append(data.Threads, d.Thread)
+		}
+	}
+	slices.Sort(data.Modules)
+	slices.Sort(data.Threads)
+
+	return data
+}
+
+func OutputReportHTML(reportName string, reportData *Report) error {
+	report, err := os.Create(reportName)
+	if err != nil {
+		return err
+	}
+
+	defer report.Close()
+
+	if err = reportTemplate.Execute(report, prepareHTMLReportData(reportData)); err != nil {
+		return err
+	}
+
+	return report.Sync()
+}
@@ -0,0 +1,156 @@
+package process
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// JUnit XML output, as rendered natively by Jenkins, GitLab and Azure DevOps
+type JUnitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    uint             `xml:"tests,attr"`
+	Failures uint             `xml:"failures,attr"`
+	Skipped  uint             `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []JUnitTestSuite `xml:"testsuite"`
+}
+
+type JUnitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	File       string          `xml:"file,attr,omitempty"`
+	Tests      uint            `xml:"tests,attr"`
+	Failures   uint            `xml:"failures,attr"`
+	Skipped    uint            `xml:"skipped,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []JUnitProperty `xml:"properties>property,omitempty"`
+	Cases      []JUnitTestCase `xml:"testcase"`
+
+	duration float64
+}
+
+type JUnitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type JUnitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *JUnitMessage `xml:"failure,omitempty"`
+	Skipped   *JUnitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type JUnitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// the name of the test without the test set, eg: Project Create e2e-project1
+func (d ReportTestDetails) TestName() string {
+	if d.Module == "" { // reports from older versions only have the combined test description
+		return d.Test
+	}
+	return fmt.Sprintf("%v %v %v", d.Module, d.CRUD, d.Object)
+}
+
+func junitTime(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
+
+// each test set becomes a testsuite, each CRUD test in the set a testcase
+func prepareJUnitData(reportData *Report) JUnitTestSuites {
+	junit := JUnitTestSuites{Name: "cx1e2e"}
+	suites := map[string]int{}
+
+	properties := []JUnitProperty{
+		{Name: "target", Value: reportData.Settings.Target},
+		{Name: "version", Value: reportData.Settings.Version.String()},
+	}
+	if reportData.Settings.Environment != "" {
+		properties = append(properties, JUnitProperty{Name: "environment", Value: reportData.Settings.Environment})
+	}
+
+	var total float64
+	for _, d := range reportData.Details {
+		key := d.Source + "|" + d.Name
+		id, ok := suites[key]
+		if !ok {
+			id = len(junit.Suites)
+			suites[key] = id
+			junit.Suites = append(junit.Suites, JUnitTestSuite{Name: d.Name, File: d.Source, Properties: properties})
+		}
+		suite := &junit.Suites[id]
+
+		testcase := JUnitTestCase{
+			Name:      d.TestName(),
+			ClassName: d.Name,
+			Time:      junitTime(d.Duration),
+		}
+
+		message := ""
+		if len(d.FailOutputs) > 0 {
+			message = d.FailOutputs[0]
+		}
+
+		switch d.ResultType {
+		case TST_FAIL:
+			testcase.Failure = &JUnitMessage{Message: message, Text: strings.Join(d.FailOutputs, "\n")}
+			suite.Failures++
+			junit.Failures++
+		case TST_SKIP:
+			testcase.Skipped = &JUnitMessage{Message: message, Text: strings.Join(d.FailOutputs, "\n")}
+			suite.Skipped++
+			junit.Skipped++
+		case TST_SLOW:
+			// JUnit has no result for slow tests, they passed functionally
+			testcase.SystemOut = d.Result
+		}
+
+		suite.Cases = append(suite.Cases, testcase)
+		suite.Tests++
+		suite.duration += d.Duration
+		junit.Tests++
+		total += d.Duration
+	}
+
+	for id := range junit.Suites {
+		junit.Suites[id].Time = junitTime(junit.Suites[id].duration)
+	}
+	// tests run in parallel with multiple threads, so the wall-clock time of the run is used if available
+	if duration, err := time.ParseDuration(reportData.Settings.Duration); err == nil {
+		total = duration.Seconds()
+	}
+	junit.Time = junitTime(total)
+
+	return junit
+}
+
+func OutputReportJUnit(reportName string, reportData *Report) error {
+	report, err := os.Create(reportName)
+	if err != nil {
+		return err
+	}
+
+	defer report.Close()
+
+	junit := prepareJUnitData(reportData)
+	data, err := xml.MarshalIndent(junit, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if _, err = report.WriteString(xml.Header); err != nil {
+		return err
+	}
+	if _, err = report.Write(data); err != nil {
+		return err
+	}
+
+	return report.Sync()
+}
@@ -0,0 +1,224 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cxpsemea/Cx1ClientGo"
+	"github.com/sirupsen/logrus"
+)
+
+// ClientFactory creates the Cx1 client of a run, using the HTTP client built from the configuration (proxy, TLS, cassette)
+type ClientFactory func(httpClient *http.Client, logger *logrus.Logger) (*Cx1ClientGo.Cx1Client, error)
+
+// RunnerOptions configure a Runner, a configuration and a client factory are required
+type RunnerOptions struct {
+	Config     *TestConfig // loaded with LoadConfig, it can only be run once since the tests keep state
+	ConfigYAML []byte      // used if Config is nil, parsed again for every run
+	ConfigDir  string      // root of the File, ZipFile and ProjectMapFile references in ConfigYAML, the working directory if empty
+
+	Client  ClientFactory
+	Logger  *logrus.Logger // the output is discarded if nil
+	Threads int            // 1 if not set
+	Engines []string       // the engines the tests may use, eg: sast, sca - DEFAULT_ENGINES if empty
+	Filter  TestFilter
+
+	// write the console summary, the report, HAR, artifact and log files, the metrics file and the history which are set in the
+	// configuration, as the run command does. Otherwise the results are only returned by Run
+	WriteOutput bool
+
+	// called from the thread running the test, so they must be safe for concurrent use with more than one thread
+	OnTestStart  func(event Event)
+	OnTestFinish func(result TestResult)
+}
+
+// TestFilter selects the tests of a run, an empty list selects everything
+type TestFilter struct {
+	Sets    []string // names of the test sets, a selected set includes its sub-sets
+	Modules []string // eg: Project, Scan
+	Labels  []string // tests with any of these labels
+}
+
+// RunHooks are called as the tests of a run start and finish
+type RunHooks struct {
+	OnTestStart  func(event Event)
+	OnTestFinish func(result TestResult)
+}
+
+// RunResult holds the results of a run, the Report is the data of the configured report files
+type RunResult struct {
+	Results   []TestResult // ordered by test ID
+	Summary   Counter
+	Report    Report
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// Runner runs the tests of a configuration from a program embedding cx1e2e
+// every run has its own state, so runners can be used concurrently, eg: against several tenants
+type Runner struct {
+	options RunnerOptions
+	used    atomic.Bool
+}
+
+func NewRunnerWithOptions(options RunnerOptions) (*Runner, error) {
+	if options.Config == nil && len(options.ConfigYAML) == 0 {
+		return nil, fmt.Errorf("no test configuration provided")
+	}
+	if options.Client == nil {
+		return nil, fmt.Errorf("no client factory provided")
+	}
+	if options.Threads <= 0 {
+		options.Threads = 1
+	}
+	if options.Logger == nil {
+		options.Logger = logrus.New()
+		options.Logger.SetOutput(io.Discard)
+	}
+
+	r := &Runner{options: options}
+	// an invalid yaml is reported now rather than on the first run
+	if options.Config == nil {
+		if _, err := r.loadConfig(); err != nil {
+			return nil, err
+		}
+	} else if !options.Config.IsValid(options.Logger) {
+		return nil, fmt.Errorf("test configuration failed to validate")
+	}
+	return r, nil
+}
+
+func (r *Runner) loadConfig() (*TestConfig, error) {
+	if r.options.Config != nil {
+		if r.used.Swap(true) {
+			return nil, fmt.Errorf("test configuration was already run, use ConfigYAML to run it more than once")
+		}
+		return r.options.Config, nil
+	}
+
+	config, err := LoadConfigYAML(r.options.Logger, r.options.ConfigYAML, r.options.ConfigDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load test configuration: %s", err)
+	}
+	if !config.IsValid(r.options.Logger) {
+		return nil, fmt.Errorf("test configuration failed to validate")
+	}
+	return &config, nil
+}
+
+// Run runs the selected tests, when the context is cancelled the running test sets finish and the results so far are returned with the context's error
+func (r *Runner) Run(ctx context.Context) (RunResult, error) {
+	logger := r.options.Logger
+	config, err := r.loadConfig()
+	if err != nil {
+		return RunResult{}, err
+	}
+
+	engines := DEFAULT_ENGINES
+	if len(r.options.Engines) > 0 {
+		engines = strings.Join(r.options.Engines, ",")
+	}
+	config.Engines = ParseEngines(engines)
+	config.Filter(r.options.Filter)
+	config.InitTestIDs()
+	if config.Telemetry == nil {
+		config.Telemetry = NewAPITelemetry()
+	}
+	if config.NewClient == nil {
+		config.NewClient = r.options.Client
+	}
+	config.Hooks = &RunHooks{OnTestStart: r.options.OnTestStart, OnTestFinish: r.options.OnTestFinish}
+
+	httpClient, err := config.CreateHTTPClient(logger)
+	if err != nil {
+		return RunResult{}, fmt.Errorf("failed to create HTTP client: %s", err)
+	}
+	cx1client, err := r.options.Client(httpClient, logger)
+	if err != nil {
+		return RunResult{}, fmt.Errorf("failed to create Cx1 client: %s", err)
+	}
+	if config.EnvironmentVersion, err = cx1client.GetVersion(); err != nil {
+		logger.Errorf("Failed to get version info: %s", err)
+	}
+
+	startTime := time.Now()
+	results, report := runTests(ctx, cx1client, logger, config, r.options.Threads, r.options.WriteOutput)
+
+	return RunResult{
+		Results:   results,
+		Summary:   report.Summary.Total,
+		Report:    report,
+		StartTime: startTime,
+		EndTime:   time.Now(),
+	}, ctx.Err()
+}
+
+// Failed returns the results of the failed tests
+func (r RunResult) Failed() []TestResult {
+	failed := []TestResult{}
+	for _, result := range r.Results {
+		if result.Result == TST_FAIL {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Filter removes the tests which are not selected, and the test sets left without tests
+func (t *TestConfig) Filter(filter TestFilter) {
+	t.Tests = filter.sets(t.Tests, false)
+	t.TestCount = t.GetTestCount()
+}
+
+func (f TestFilter) sets(sets []TestSet, parentSelected bool) []TestSet {
+	kept := []TestSet{}
+	for _, set := range sets {
+		selected := parentSelected || len(f.Sets) == 0 || containsFold(f.Sets, set.Name)
+		set.SubTests = f.sets(set.SubTests, selected)
+		for module, tests := range set.Modules {
+			set.Modules[module] = slices.DeleteFunc(tests, func(test TestRunner) bool {
+				return !selected || !f.matches(test)
+			})
+		}
+		if set.GetTestCount() > 0 {
+			kept = append(kept, set)
+		}
+	}
+	return kept
+}
+
+func (f TestFilter) matches(test TestRunner) bool {
+	if len(f.Modules) > 0 && !containsFold(f.Modules, test.GetModule()) {
+		return false
+	}
+	if len(f.Labels) > 0 && !slices.ContainsFunc(test.GetLabels(), func(label string) bool { return containsFold(f.Labels, label) }) {
+		return false
+	}
+	return true
+}
+
+func containsFold(list []string, value string) bool {
+	return slices.ContainsFunc(list, func(s string) bool { return strings.EqualFold(s, value) })
+}
+
+func (h *RunHooks) TestStarted(Config *TestConfig, event Event) {
+	if h == nil || h.OnTestStart == nil {
+		return
+	}
+	event.Time = time.Now()
+	event.Environment = Config.Environment
+	h.OnTestStart(event)
+}
+
+func (h *RunHooks) TestFinished(result TestResult) {
+	if h == nil || h.OnTestFinish == nil {
+		return
+	}
+	h.OnTestFinish(result)
+}
@@ -0,0 +1,151 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/cxpsemea/Cx1ClientGo"
+	"github.com/cxpsemea/cx1e2e/pkg/mockcx1"
+	"github.com/sirupsen/logrus"
+)
+
+const runnerConfigYAML = `
+Tests:
+  - Name: projects
+    Groups:
+      - Name: e2e-runner-group
+        Test: CD
+    Projects:
+      - Name: e2e-runner-project
+        Test: CD
+        Labels: [critical]
+  - Name: users
+    Users:
+      - Name: e2e-runner-user
+        Email: e2e-runner-user@cx.local
+        Test: CD
+`
+
+func mockClientFactory(baseURL string) ClientFactory {
+	return func(httpClient *http.Client, logger *logrus.Logger) (*Cx1ClientGo.Cx1Client, error) {
+		config := Cx1ClientGo.Cx1ClientConfiguration{
+			HttpClient: httpClient,
+			Logger:     logger,
+			Cx1Url:     baseURL,
+			IAMUrl:     baseURL,
+			Tenant:     "mock",
+		}
+		config.Auth.ClientID = "mock-client"
+		config.Auth.ClientSecret = "mock-secret"
+		return Cx1ClientGo.NewClientWithOptions(config)
+	}
+}
+
+// runs the configuration against the mock and checks that every test passed, with IDs from 1 in execution order
+// it does not stop the test, so that it can be called from other goroutines
+func checkRun(t *testing.T, runner *Runner) {
+	t.Helper()
+	result, err := runner.Run(context.Background())
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if len(result.Results) != 6 {
+		t.Errorf("expected 6 results, got %d", len(result.Results))
+		return
+	}
+	for id, r := range result.Results {
+		if r.Result != TST_PASS {
+			t.Errorf("%v %v %v: %v", r.CRUD, r.Module, r.TestObject, r.Reason)
+		}
+		if r.Id != uint(id+1) {
+			t.Errorf("%v %v %v has ID %d, expected %d", r.CRUD, r.Module, r.TestObject, r.Id, id+1)
+		}
+	}
+}
+
+func TestRunnerRunTwice(t *testing.T) {
+	server := httptest.NewServer(mockcx1.New(mockcx1.Options{}))
+	defer server.Close()
+	t.Chdir(t.TempDir())
+
+	runner, err := NewRunnerWithOptions(RunnerOptions{ConfigYAML: []byte(runnerConfigYAML), Client: mockClientFactory(server.URL)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkRun(t, runner)
+	checkRun(t, runner)
+
+	// nothing is written to the working directory without WriteOutput
+	if files, _ := os.ReadDir("."); len(files) > 0 {
+		t.Errorf("the runs wrote %d files", len(files))
+	}
+}
+
+func TestRunnerConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for range 3 {
+		server := httptest.NewServer(mockcx1.New(mockcx1.Options{}))
+		defer server.Close()
+		runner, err := NewRunnerWithOptions(RunnerOptions{ConfigYAML: []byte(runnerConfigYAML), Client: mockClientFactory(server.URL), Threads: 2})
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			checkRun(t, runner)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRunnerConfigRunsOnce(t *testing.T) {
+	config, err := LoadConfigYAML(logrus.New(), []byte(runnerConfigYAML), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := NewRunnerWithOptions(RunnerOptions{
+		Config: &config,
+		Client: func(*http.Client, *logrus.Logger) (*Cx1ClientGo.Cx1Client, error) {
+			return nil, fmt.Errorf("no tenant")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runner.Run(context.Background()); err == nil {
+		t.Fatalf("run without a client did not fail")
+	}
+	if _, err := runner.Run(context.Background()); err == nil {
+		t.Errorf("a loaded configuration was run twice")
+	}
+}
+
+func TestConfigFilter(t *testing.T) {
+	for _, tc := range []struct {
+		filter TestFilter
+		sets   int
+		tests  int
+	}{
+		{TestFilter{}, 2, 3},
+		{TestFilter{Sets: []string{"USERS"}}, 1, 1},
+		{TestFilter{Modules: []string{"project", "User"}}, 2, 2},
+		{TestFilter{Labels: []string{"critical"}}, 1, 1},
+		{TestFilter{Sets: []string{"users"}, Labels: []string{"critical"}}, 0, 0},
+	} {
+		config, err := LoadConfigYAML(logrus.New(), []byte(runnerConfigYAML), "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		config.Filter(tc.filter)
+		if len(config.Tests) != tc.sets || config.TestCount != tc.tests {
+			t.Errorf("filter %+v kept %d sets with %d tests, expected %d with %d", tc.filter, len(config.Tests), config.TestCount, tc.sets, tc.tests)
+		}
+	}
+}
@@ -0,0 +1,272 @@
+package process
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+)
+
+// lint checks are run over the whole configuration (across test sets) and catch mistakes that the per-test Validate can't see
+type LintIssue struct {
+	Source  string
+	Test    string
+	Message string
+}
+
+func (i LintIssue) String() string {
+	if i.Test == "" {
+		return fmt.Sprintf("[%v] %v", i.Source, i.Message)
+	}
+	return fmt.Sprintf("[%v] %v: %v", i.Source, i.Test, i.Message)
+}
+
+type lintObject struct {
+	Module string
+	Name   string
+}
+
+func (o lintObject) String() string {
+	return fmt.Sprintf("%v '%v'", o.Module, o.Name)
+}
+
+type lintEvent struct {
+	Set    *TestSet
+	CRUD   string
+	Test   TestRunner
+	Object lintObject   // the object targeted by the test, Name is empty if the test does not target a named object
+	Refs   []lintObject // other objects which must exist for the test to work
+}
+
+func (e lintEvent) String() string {
+	return fmt.Sprintf("test set '%v' %v %v %v", e.Set.Name, e.CRUD, e.Test.GetModule(), e.Test.String())
+}
+
+type lintRef struct {
+	Test TestRunner
+	Ref  lintObject
+}
+
+// references to these modules must be created by a test or listed under PreExisting
+var lintRefModules = []string{types.MOD_GROUP, types.MOD_PRESET, types.MOD_PROJECT, types.MOD_ROLE}
+
+func (t *TestConfig) Lint() []LintIssue {
+	events := []lintEvent{}
+	for id := range t.Tests {
+		events = t.Tests[id].lintEvents(events)
+	}
+
+	created := map[lintObject]bool{}
+	for _, e := range events {
+		if e.CRUD == types.OP_CREATE && e.Object.Name != "" && !e.Test.IsNegative() {
+			created[e.Object] = true
+		}
+	}
+
+	issues := []LintIssue{}
+	known := map[lintObject]bool{}
+	scanned := map[string]bool{}
+	reported := map[lintRef]bool{}
+	threads := map[lintObject]map[uint][]string{}
+	objects := []lintObject{}
+
+	for _, e := range events {
+		if !e.Test.IsNegative() { // negative tests are expected to target missing objects
+			switch e.CRUD {
+			case types.OP_DELETE:
+				if e.Object.Name != "" && !known[e.Object] && !t.PreExisting.Contains(e.Object.Module, e.Object.Name) {
+					issues = append(issues, LintIssue{e.Test.GetSource(), e.String(), fmt.Sprintf("deletes %v which no earlier test creates or reads", e.Object)})
+				}
+			case types.OP_UPDATE:
+				if result, ok := e.Test.(*types.ResultCRUD); ok && !scanned[result.ProjectName] {
+					issues = append(issues, LintIssue{e.Test.GetSource(), e.String(), fmt.Sprintf("updates results of project '%v' but there is no earlier scan of this project", result.ProjectName)})
+				}
+			}
+
+			for _, ref := range e.Refs {
+				if !slices.Contains(lintRefModules, ref.Module) || created[ref] || t.PreExisting.Contains(ref.Module, ref.Name) || reported[lintRef{e.Test, ref}] {
+					continue
+				}
+				reported[lintRef{e.Test, ref}] = true
+				issues = append(issues, LintIssue{e.Test.GetSource(), e.String(), fmt.Sprintf("references %v which is not created by any test and is not listed under PreExisting", ref)})
+			}
+
+			if e.CRUD == types.OP_CREATE || e.CRUD == types.OP_READ {
+				known[e.Object] = true
+				if scan, ok := e.Test.(*types.ScanCRUD); ok {
+					scanned[scan.Project] = true
+				}
+			}
+		}
+
+		if t.MultiThreadable && e.Set.Thread != 0 {
+			for _, o := range append([]lintObject{e.Object}, e.Refs...) {
+				if o.Name == "" {
+					continue
+				}
+				if _, ok := threads[o]; !ok {
+					threads[o] = map[uint][]string{}
+					objects = append(objects, o)
+				}
+				if !slices.Contains(threads[o][e.Set.Thread], e.Set.Name) {
+					threads[o][e.Set.Thread] = append(threads[o][e.Set.Thread], e.Set.Name)
+				}
+			}
+		}
+	}
+
+	for _, o := range objects {
+		if len(threads[o]) < 2 {
+			continue
+		}
+		ids := []uint{}
+		for thread := range threads[o] {
+			ids = append(ids, thread)
+		}
+		slices.Sort(ids)
+
+		usage := []string{}
+		for _, thread := range ids {
+			usage = append(usage, fmt.Sprintf("thread %d (%v)", thread, strings.Join(threads[o][thread], ", ")))
+		}
+		issues = append(issues, LintIssue{t.ConfigPath, "", fmt.Sprintf("%v is used by test sets pinned to different threads: %v", o, strings.Join(usage, ", "))})
+	}
+
+	return issues
+}
+
+// lint events are generated in the same order as tests are executed by a single thread
+func (t *TestSet) lintEvents(events []lintEvent) []lintEvent {
+	if len(t.SubTests) > 0 {
+		for id := range t.SubTests {
+			events = t.SubTests[id].lintEvents(events)
+		}
+		return events
+	}
+
+	for _, CRUD := range []string{types.OP_CREATE, types.OP_READ, types.OP_UPDATE, types.OP_DELETE} {
+		for _, test := range t.GetTests(CRUD) {
+			if test.IsType(CRUD) {
+				object, refs := lintTarget(test)
+				events = append(events, lintEvent{Set: t, CRUD: CRUD, Test: test, Object: object, Refs: refs})
+			}
+		}
+	}
+
+	return events
+}
+
+func lintTarget(test TestRunner) (lintObject, []lintObject) {
+	var object lintObject
+	refs := []lintObject{}
+
+	refList := func(module string, names []string) {
+		for _, name := range names {
+			if name != "" {
+				refs = append(refs, lintObject{module, name})
+			}
+		}
+	}
+
+	switch t := test.(type) {
+	case *types.AccessAssignmentCRUD:
+		object = lintObject{types.MOD_ACCESS, t.String()}
+		switch t.EntityType {
+		case "user":
+			refList(types.MOD_USER, []string{t.EntityName})
+		case "group":
+			refList(types.MOD_GROUP, []string{t.EntityName})
+		}
+		switch t.ResourceType {
+		case "application":
+			refList(types.MOD_APPLICATION, []string{t.ResourceName})
+		case "project":
+			refList(types.MOD_PROJECT, []string{t.ResourceName})
+		}
+		refList(types.MOD_ROLE, t.Roles)
+	case *types.AnalyticsCRUD:
+		refList(types.MOD_PROJECT, t.Filter.Projects)
+	case *types.ApplicationCRUD:
+		object = lintObject{types.MOD_APPLICATION, t.Name}
+		refList(types.MOD_GROUP, t.Groups)
+		if t.Projects != nil {
+			refList(types.MOD_PROJECT, *t.Projects)
+		}
+	case *types.BranchCRUD:
+		refList(types.MOD_PROJECT, []string{t.Project})
+	case *types.CxQLCRUD:
+		object = lintObject{types.MOD_QUERY, t.String()}
+		refList(types.MOD_PROJECT, []string{t.Scope.Project})
+		refList(types.MOD_APPLICATION, []string{t.Scope.Application})
+	case *types.GroupCRUD:
+		name := t.Name
+		if name == "" {
+			parts := strings.Split(t.Path, "/")
+			name = parts[len(parts)-1]
+		}
+		object = lintObject{types.MOD_GROUP, name}
+		refList(types.MOD_GROUP, []string{t.Parent})
+	case *types.OIDCClientCRUD:
+		object = lintObject{types.MOD_CLIENT, t.Name}
+		refList(types.MOD_GROUP, t.Groups)
+		refList(types.MOD_ROLE, t.Roles)
+	case *types.PresetCRUD:
+		object = lintObject{types.MOD_PRESET, t.Name}
+	case *types.ProjectCRUD:
+		object = lintObject{types.MOD_PROJECT, t.Name}
+		if t.Groups != nil {
+			refList(types.MOD_GROUP, *t.Groups)
+		}
+		if t.Applications != nil {
+			refList(types.MOD_APPLICATION, *t.Applications)
+		}
+		refList(types.MOD_PRESET, []string{t.Preset})
+	case *types.ReportCRUD:
+		refList(types.MOD_PROJECT, t.ProjectNames)
+	case *types.ResultCRUD:
+		refList(types.MOD_PROJECT, []string{t.ProjectName})
+	case *types.RoleCRUD:
+		object = lintObject{types.MOD_ROLE, t.Name}
+	case *types.ScanCRUD:
+		object = lintObject{types.MOD_SCAN, t.Project}
+		refList(types.MOD_PROJECT, []string{t.Project})
+		refList(types.MOD_PRESET, []string{t.SASTPreset, t.IACPreset})
+	case *types.UserCRUD:
+		object = lintObject{types.MOD_USER, t.Name}
+		refList(types.MOD_GROUP, t.Groups)
+		refList(types.MOD_ROLE, t.Roles)
+	}
+
+	return object, refs
+}
+
+func (p PreExistingObjects) Contains(module, name string) bool {
+	switch module {
+	case types.MOD_APPLICATION:
+		return slices.Contains(p.Applications, name)
+	case types.MOD_CLIENT:
+		return slices.Contains(p.Clients, name)
+	case types.MOD_GROUP:
+		return slices.Contains(p.Groups, name)
+	case types.MOD_PRESET:
+		return slices.Contains(p.Presets, name)
+	case types.MOD_PROJECT:
+		return slices.Contains(p.Projects, name)
+	case types.MOD_ROLE:
+		return slices.Contains(p.Roles, name)
+	case types.MOD_USER:
+		return slices.Contains(p.Users, name)
+	}
+	return false
+}
+
+func (p *PreExistingObjects) Merge(o *PreExistingObjects) {
+	p.Applications = append(p.Applications, o.Applications...)
+	p.Clients = append(p.Clients, o.Clients...)
+	p.Groups = append(p.Groups, o.Groups...)
+	p.Presets = append(p.Presets, o.Presets...)
+	p.Projects = append(p.Projects, o.Projects...)
+	p.Roles = append(p.Roles, o.Roles...)
+	p.Users = append(p.Users, o.Users...)
+}
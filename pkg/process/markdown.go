@@ -0,0 +1,156 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// escapes text for use in a GitHub-flavored markdown table cell
+func markdownCell(text string) string {
+	replacer := strings.NewReplacer("|", "\\|", "<", "&lt;", ">", "&gt;", "\r", "", "\n", "<br>")
+	return replacer.Replace(text)
+}
+
+func markdownCount(c *Counter) string {
+	if c.Count() == 0 {
+		return ""
+	}
+	extra := []string{}
+	if c.Flaky > 0 {
+		extra = append(extra, fmt.Sprintf("%d flaky", c.Flaky))
+	}
+	if c.Slow > 0 {
+		extra = append(extra, fmt.Sprintf("%d slow", c.Slow))
+	}
+	if len(extra) > 0 {
+		return fmt.Sprintf("%d / %d / %d (%v)", c.Pass, c.Fail, c.Skip, strings.Join(extra, ", "))
+	}
+	return fmt.Sprintf("%d / %d / %d", c.Pass, c.Fail, c.Skip)
+}
+
+func markdownSeconds(seconds float64) string {
+	if seconds == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%vs", seconds)
+}
+
+// a compact report for pull request comments and CI step summaries
+func RenderReportMarkdown(reportData *Report) string {
+	var md strings.Builder
+	total := &reportData.Summary.Total
+
+	status := "PASS"
+	if total.Fail > 0 {
+		status = "FAIL"
+	}
+	md.WriteString(fmt.Sprintf("## cx1e2e %v: %v\n\n", status, markdownCell(reportData.Settings.Target)))
+	md.WriteString(fmt.Sprintf("**%d** passed, **%d** failed, **%d** skipped", total.Pass, total.Fail, total.Skip))
+	if total.Slow > 0 {
+		md.WriteString(fmt.Sprintf(", **%d** slow", total.Slow))
+	}
+	md.WriteString(fmt.Sprintf(" out of %d tests in %v (%d threads)\n\n", total.Count(), reportData.Settings.Duration, reportData.Settings.Threads))
+
+	md.WriteString("| Area | Create | Read | Update | Delete |\n")
+	md.WriteString("|---|---|---|---|---|\n")
+	for _, area := range reportData.Summary.Areas() {
+		c := area.Count
+		md.WriteString(fmt.Sprintf("| %v | %v | %v | %v | %v |\n", area.Label, markdownCount(&c.Create), markdownCount(&c.Read), markdownCount(&c.Update), markdownCount(&c.Delete)))
+	}
+	md.WriteString("\n_Counts are pass / fail / skip, flaky tests passed after being retried, slow tests passed but exceeded their maximum duration._\n\n")
+
+	if total.Fail > 0 {
+		md.WriteString("### Failures\n\n")
+		md.WriteString("| Test Set | Test | Reason |\n")
+		md.WriteString("|---|---|---|\n")
+		for _, d := range reportData.Details {
+			if d.ResultType != TST_FAIL {
+				continue
+			}
+			md.WriteString(fmt.Sprintf("| %v<br>(%v) | %v | %v |\n", markdownCell(d.Name), markdownCell(d.Source), markdownCell(d.TestName()), markdownCell(strings.Join(d.FailOutputs, "\n"))))
+		}
+		md.WriteString("\n")
+	}
+
+	if reportData.SLAViolations() > 0 {
+		md.WriteString("### Performance budgets exceeded\n\n")
+		md.WriteString("| Test Set | Test | Duration | Warn | Max | Status |\n")
+		md.WriteString("|---|---|---|---|---|---|\n")
+		for _, e := range reportData.SLA {
+			if e.Status == "OK" {
+				continue
+			}
+			md.WriteString(fmt.Sprintf("| %v | %v | %.2fs | %v | %v | %v |\n", markdownCell(e.Name), markdownCell(e.Test), e.Duration, markdownSeconds(e.Budget.WarnDuration), markdownSeconds(e.Budget.MaxDuration), e.Status))
+		}
+		md.WriteString("\n")
+	}
+
+	if total.Skip > 0 {
+		reasons := []string{}
+		skipped := map[string][]string{}
+		for _, d := range reportData.Details {
+			if d.ResultType != TST_SKIP {
+				continue
+			}
+			reason := ""
+			if len(d.FailOutputs) > 0 {
+				reason = d.FailOutputs[0]
+			}
+			if _, ok := skipped[reason]; !ok {
+				reasons = append(reasons, reason)
+			}
+			skipped[reason] = append(skipped[reason], fmt.Sprintf("%v - %v", d.Name, d.TestName()))
+		}
+
+		md.WriteString("### Skipped\n\n")
+		for _, reason := range reasons {
+			md.WriteString(fmt.Sprintf("<details><summary>%v (%d)</summary>\n\n", markdownCell(reason), len(skipped[reason])))
+			for _, test := range skipped[reason] {
+				md.WriteString(fmt.Sprintf("- %v\n", markdownCell(test)))
+			}
+			md.WriteString("\n</details>\n\n")
+		}
+	}
+
+	md.WriteString("### Settings\n\n")
+	md.WriteString("| Setting | Value |\n")
+	md.WriteString("|---|---|\n")
+	if reportData.Settings.Environment != "" {
+		md.WriteString(fmt.Sprintf("| Environment | %v |\n", markdownCell(reportData.Settings.Environment)))
+	}
+	md.WriteString(fmt.Sprintf("| Target | %v |\n", markdownCell(reportData.Settings.Target)))
+	md.WriteString(fmt.Sprintf("| Version | %v |\n", markdownCell(reportData.Settings.Version.String())))
+	md.WriteString(fmt.Sprintf("| Authentication | %v |\n", markdownCell(reportData.Settings.Auth)))
+	md.WriteString(fmt.Sprintf("| Configuration | %v |\n", markdownCell(reportData.Settings.Config)))
+	md.WriteString(fmt.Sprintf("| Started | %v |\n", markdownCell(reportData.Settings.StartTime)))
+	md.WriteString(fmt.Sprintf("| Finished | %v |\n", markdownCell(reportData.Settings.EndTime)))
+	if reportData.Settings.E2ESuffix != "" {
+		md.WriteString(fmt.Sprintf("| E2E_RUN_SUFFIX | %v |\n", markdownCell(reportData.Settings.E2ESuffix)))
+	}
+
+	return md.String()
+}
+
+// writes the markdown report, and appends it to the GitHub Actions step summary when running in a workflow
+func OutputReportMarkdown(reportName string, reportData *Report) error {
+	markdown := RenderReportMarkdown(reportData)
+
+	if err := os.WriteFile(reportName, []byte(markdown), 0644); err != nil {
+		return err
+	}
+
+	if summaryFile := os.Getenv("GITHUB_STEP_SUMMARY"); summaryFile != "" {
+		summary, err := os.OpenFile(summaryFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open GITHUB_STEP_SUMMARY file %v: %s", summaryFile, err)
+		}
+		defer summary.Close()
+
+		if _, err = summary.WriteString(markdown + "\n"); err != nil {
+			return fmt.Errorf("failed to append to GITHUB_STEP_SUMMARY file %v: %s", summaryFile, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,231 @@
+package process
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	testDurationBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+	apiLatencyBuckets   = []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+)
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64 // cumulative counts are calculated when writing
+	count   uint64
+	sum     float64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(value float64) {
+	for id, bound := range h.buckets {
+		if value <= bound {
+			h.counts[id]++
+			break
+		}
+	}
+	h.count++
+	h.sum += value
+}
+
+// Metrics collects the results of a run in Prometheus text exposition format
+// the label values of each series are joined with \x00 to form the map keys
+type Metrics struct {
+	lock         sync.Mutex
+	tests        map[string]uint64
+	testDuration map[string]*histogram
+	scanDuration map[string]*histogram
+	apiLatency   map[string]*histogram
+	apiStatus    map[string]uint64
+	retries      map[string]uint64
+	lastRun      map[string]float64
+}
+
+func NewMetrics() *Metrics {
+	return &Metrics{
+		tests:        map[string]uint64{},
+		testDuration: map[string]*histogram{},
+		scanDuration: map[string]*histogram{},
+		apiLatency:   map[string]*histogram{},
+		apiStatus:    map[string]uint64{},
+		retries:      map[string]uint64{},
+		lastRun:      map[string]float64{},
+	}
+}
+
+func metricKey(values ...string) string {
+	return strings.Join(values, "\x00")
+}
+
+func observe(series map[string]*histogram, buckets []float64, key string, value float64) {
+	h, ok := series[key]
+	if !ok {
+		h = newHistogram(buckets)
+		series[key] = h
+	}
+	h.observe(value)
+}
+
+// the engines of a scan test, eg: sast+sca
+func scanEngines(test TestRunner) string {
+	if scan, ok := test.(*types.ScanCRUD); ok {
+		return strings.Join(strings.Fields(scan.Engine), "+")
+	}
+	return ""
+}
+
+func (m *Metrics) ObserveTest(Config *TestConfig, test TestRunner, result *TestResult) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	env := historyEnvironment(Config)
+	m.tests[metricKey(env, result.Module, result.CRUD, ResultString(result.Result))]++
+	if result.Result == TST_SKIP {
+		return
+	}
+
+	observe(m.testDuration, testDurationBuckets, metricKey(env, result.Module, result.CRUD), result.Duration)
+	if result.Module == types.MOD_SCAN && result.CRUD == types.OP_CREATE && (result.Result == TST_PASS || result.Result == TST_SLOW) {
+		observe(m.scanDuration, testDurationBuckets, metricKey(env, scanEngines(test)), result.Duration)
+	}
+
+	for _, c := range result.Calls {
+		observe(m.apiLatency, apiLatencyBuckets, metricKey(env, c.Method, c.Path), c.Latency)
+		status := strconv.Itoa(c.Status)
+		if c.Error != "" {
+			status = "error"
+		}
+		m.apiStatus[metricKey(env, c.Method, c.Path, status)]++
+	}
+}
+
+func (m *Metrics) ObserveRetry(Config *TestConfig, module, CRUD string) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.retries[metricKey(historyEnvironment(Config), module, CRUD)]++
+}
+
+func (m *Metrics) ObserveRun(Config *TestConfig, endTime time.Time) {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.lastRun[metricKey(historyEnvironment(Config))] = float64(endTime.Unix())
+}
+
+func labelString(names []string, key string, extra ...string) string {
+	values := strings.Split(key, "\x00")
+	labels := []string{}
+	for id, name := range names {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(values[id])
+		labels = append(labels, fmt.Sprintf(`%v="%v"`, name, value))
+	}
+	labels = append(labels, extra...)
+	return "{" + strings.Join(labels, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+func sortedKeys[V any](series map[string]V) []string {
+	keys := make([]string, 0, len(series))
+	for key := range series {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func writeCounter[V uint64 | float64](w io.Writer, name, metricType, help string, labels []string, series map[string]V) {
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, metricType)
+	for _, key := range sortedKeys(series) {
+		fmt.Fprintf(w, "%v%v %v\n", name, labelString(labels, key), formatFloat(float64(series[key])))
+	}
+}
+
+func writeHistogram(w io.Writer, name, help string, labels []string, series map[string]*histogram) {
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v histogram\n", name, help, name)
+	for _, key := range sortedKeys(series) {
+		h := series[key]
+		var cumulative uint64
+		for id, bound := range h.buckets {
+			cumulative += h.counts[id]
+			fmt.Fprintf(w, "%v_bucket%v %d\n", name, labelString(labels, key, fmt.Sprintf(`le="%v"`, formatFloat(bound))), cumulative)
+		}
+		fmt.Fprintf(w, "%v_bucket%v %d\n", name, labelString(labels, key, `le="+Inf"`), h.count)
+		fmt.Fprintf(w, "%v_sum%v %v\n", name, labelString(labels, key), formatFloat(h.sum))
+		fmt.Fprintf(w, "%v_count%v %d\n", name, labelString(labels, key), h.count)
+	}
+}
+
+func (m *Metrics) Write(w io.Writer) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	writeCounter(w, "cx1e2e_tests_total", "counter", "Number of tests run, by module, CRUD operation and result.", []string{"environment", "module", "crud", "result"}, m.tests)
+	writeHistogram(w, "cx1e2e_test_duration_seconds", "Duration of tests which were not skipped.", []string{"environment", "module", "crud"}, m.testDuration)
+	writeHistogram(w, "cx1e2e_scan_duration_seconds", "Duration of successful scans, by engines.", []string{"environment", "engine"}, m.scanDuration)
+	writeHistogram(w, "cx1e2e_api_request_duration_seconds", "Latency of the API requests made by the tests.", []string{"environment", "method", "path"}, m.apiLatency)
+	writeCounter(w, "cx1e2e_api_requests_total", "counter", "Number of API requests made by the tests, by status code.", []string{"environment", "method", "path", "status"}, m.apiStatus)
+	writeCounter(w, "cx1e2e_test_retries_total", "counter", "Number of times a failed test was retried.", []string{"environment", "module", "crud"}, m.retries)
+	writeCounter(w, "cx1e2e_last_run_timestamp_seconds", "gauge", "Time at which the last run finished.", []string{"environment"}, m.lastRun)
+}
+
+// WriteFile writes the metrics for the node_exporter textfile collector, which requires the file to be replaced atomically
+func (m *Metrics) WriteFile(filename string) error {
+	temp, err := os.CreateTemp(filepath.Dir(filename), ".cx1e2e_metrics_*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	m.Write(temp)
+	if err = temp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(temp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), filename)
+}
+
+// Serve exposes the metrics on http://<address>/metrics while the tests are running
+func (m *Metrics) Serve(address string, logger *logrus.Logger) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.Write(w)
+	})
+
+	server := &http.Server{Addr: address, Handler: mux}
+	go func() {
+		logger.Infof("Serving metrics on http://%v/metrics", address)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Errorf("Failed to serve metrics on %v: %s", address, err)
+		}
+	}()
+	return server
+}
@@ -0,0 +1,159 @@
+package process
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+)
+
+// Module is a type of test which can be configured in a test set
+type Module struct {
+	Name           string                                                        // as returned by GetModule() of its tests, eg: Project
+	Key            string                                                        // the list of tests in a test set, eg: Projects
+	Label          string                                                        // in the report summary, the Name if empty
+	Priority       int                                                           // Create, Read and Update tests of a test set run by ascending priority
+	DeletePriority int                                                           // Delete tests run by ascending priority, usually the reverse of Priority. 0 if the module does not delete
+	New            func(unmarshal func(interface{}) error) ([]TestRunner, error) // creates the tests from the YAML list under Key
+}
+
+// the built-in modules: objects are created before the objects which depend on them, and deleted in the reverse order
+var modules = []Module{
+	{Name: types.MOD_FLAG, Key: "Flags", Priority: 10, New: NewTests[types.FlagCRUD]},
+	{Name: types.MOD_ANALYTICS, Key: "Analytics", Priority: 20, New: NewTests[types.AnalyticsCRUD]},
+	{Name: types.MOD_IMPORT, Key: "Imports", Priority: 30, New: NewTests[types.ImportCRUD]},
+	{Name: types.MOD_GROUP, Key: "Groups", Priority: 40, DeletePriority: 100, New: NewTests[types.GroupCRUD]},
+	{Name: types.MOD_APPLICATION, Key: "Applications", Priority: 50, DeletePriority: 90, New: NewTests[types.ApplicationCRUD]},
+	{Name: types.MOD_PROJECT, Key: "Projects", Priority: 60, DeletePriority: 80, New: NewTests[types.ProjectCRUD]},
+	{Name: types.MOD_ROLE, Key: "Roles", Priority: 70, DeletePriority: 70, New: NewTests[types.RoleCRUD]},
+	{Name: types.MOD_USER, Key: "Users", Priority: 80, DeletePriority: 60, New: NewTests[types.UserCRUD]},
+	{Name: types.MOD_CLIENT, Key: "OIDCClients", Label: "Client", Priority: 90, DeletePriority: 50, New: NewTests[types.OIDCClientCRUD]},
+	{Name: types.MOD_ACCESS, Key: "AccessAssignments", Label: "Access Assignment", Priority: 100, DeletePriority: 40, New: NewTests[types.AccessAssignmentCRUD]},
+	{Name: types.MOD_QUERY, Key: "Queries", Priority: 110, DeletePriority: 30, New: NewTests[types.CxQLCRUD]},
+	{Name: types.MOD_PRESET, Key: "Presets", Priority: 120, DeletePriority: 20, New: NewTests[types.PresetCRUD]},
+	{Name: types.MOD_SCAN, Key: "Scans", Priority: 130, DeletePriority: 10, New: NewTests[types.ScanCRUD]},
+	{Name: types.MOD_BRANCH, Key: "Branches", Label: "Branches", Priority: 140, New: NewTests[types.BranchCRUD]},
+	{Name: types.MOD_RESULT, Key: "Results", Priority: 150, New: newResultTests},
+	{Name: types.MOD_REPORT, Key: "Reports", Priority: 160, New: NewTests[types.ReportCRUD]},
+}
+
+// RegisterModule adds a type of test, eg: from an init function of a package with tests for a private API
+func RegisterModule(module Module) error {
+	if module.Name == "" || module.Key == "" || module.New == nil {
+		return fmt.Errorf("module requires a Name, Key and New function")
+	}
+	for _, m := range modules {
+		if m.Name == module.Name {
+			return fmt.Errorf("module %v is already registered", module.Name)
+		}
+		if m.Key == module.Key {
+			return fmt.Errorf("key %v is already used by module %v", module.Key, m.Name)
+		}
+	}
+	modules = append(modules, module)
+	return nil
+}
+
+// Modules returns the registered modules in the order their tests run for the CRUD operation
+func Modules(CRUD string) []Module {
+	list := []Module{}
+	for _, m := range modules {
+		if CRUD != types.OP_DELETE || m.DeletePriority > 0 {
+			list = append(list, m)
+		}
+	}
+	slices.SortStableFunc(list, func(a, b Module) int {
+		if CRUD == types.OP_DELETE {
+			return a.DeletePriority - b.DeletePriority
+		}
+		return a.Priority - b.Priority
+	})
+	return list
+}
+
+func GetModule(name string) (Module, bool) {
+	for _, m := range modules {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
+
+// NewTests creates the tests of a module whose tests are of type T, for Module.New
+func NewTests[T any, PT interface {
+	*T
+	TestRunner
+}](unmarshal func(interface{}) error) ([]TestRunner, error) {
+	var tests []T
+	if err := unmarshal(&tests); err != nil {
+		return nil, err
+	}
+	runners := make([]TestRunner, len(tests))
+	for id := range tests {
+		runners[id] = PT(&tests[id])
+	}
+	return runners, nil
+}
+
+func newResultTests(unmarshal func(interface{}) error) ([]TestRunner, error) {
+	tests, err := NewTests[types.ResultCRUD](unmarshal)
+	for _, test := range tests {
+		if result := test.(*types.ResultCRUD); result.Number == 0 {
+			result.Number = 1
+		}
+	}
+	return tests, err
+}
+
+// keeps the YAML of a field, so that it can be decoded into the tests of its module without being re-encoded
+type yamlField struct {
+	unmarshal func(interface{}) error
+}
+
+func (f *yamlField) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	f.unmarshal = unmarshal
+	return nil
+}
+
+// the test set is read field by field, so that the tests of each registered module can be created from their key
+func (t *TestSet) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	type testSet TestSet
+	var set testSet
+	if err := unmarshal(&set); err != nil {
+		return err
+	}
+	var fields map[string]*yamlField
+	if err := unmarshal(&fields); err != nil {
+		return err
+	}
+
+	*t = TestSet(set)
+	t.Modules = map[string][]TestRunner{}
+	for _, m := range modules {
+		field, ok := fields[m.Key]
+		if !ok || field == nil {
+			continue
+		}
+		tests, err := m.New(field.unmarshal)
+		if err != nil {
+			return fmt.Errorf("test set %v: %v: %s", t.Name, m.Key, err)
+		}
+		t.Modules[m.Name] = tests
+	}
+	return nil
+}
+
+// GetTests returns the tests in this set (excluding subtests) in the order they are executed for the given CRUD operation
+func (t *TestSet) GetTests(CRUD string) []TestRunner {
+	tests := []TestRunner{}
+	for _, m := range Modules(CRUD) {
+		tests = append(tests, t.Modules[m.Name]...)
+	}
+	return tests
+}
+
+// AllTests returns every test in this set (excluding subtests), including those of modules which do not delete
+func (t *TestSet) AllTests() []TestRunner {
+	return t.GetTests(types.OP_CREATE)
+}
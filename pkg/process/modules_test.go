@@ -0,0 +1,110 @@
+package process
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+	"gopkg.in/yaml.v2"
+)
+
+// decodes the module lists of the test sets into typed fields, as they were before the module registry
+func typedTestSets(t *testing.T, data []byte) reflect.Value {
+	fields := []reflect.StructField{}
+	for _, m := range modules {
+		tests, err := m.New(func(v interface{}) error { return yaml.Unmarshal([]byte("[{}]"), v) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields = append(fields, reflect.StructField{
+			Name: m.Key,
+			Type: reflect.SliceOf(reflect.TypeOf(tests[0]).Elem()),
+			Tag:  reflect.StructTag(`yaml:"` + m.Key + `"`),
+		})
+	}
+	config := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name: "Tests",
+		Type: reflect.SliceOf(reflect.StructOf(fields)),
+		Tag:  `yaml:"Tests"`,
+	}}))
+	if err := yaml.Unmarshal(data, config.Interface()); err != nil {
+		t.Fatal(err)
+	}
+	return config.Elem().Field(0)
+}
+
+func TestModuleListsMatchTypedDecoding(t *testing.T) {
+	files := []string{}
+	err := filepath.Walk("../../examples", func(path string, info os.FileInfo, err error) error {
+		if err == nil && strings.HasSuffix(path, ".yaml") {
+			files = append(files, path)
+		}
+		return err
+	})
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no example configurations found: %v", err)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var config struct {
+			Tests []TestSet `yaml:"Tests"`
+		}
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			t.Fatalf("%v: %s", file, err)
+		}
+
+		typed := typedTestSets(t, data)
+		for id, set := range config.Tests {
+			for _, m := range modules {
+				list := typed.Index(id).FieldByName(m.Key)
+				var expected []TestRunner
+				if list.Len() > 0 {
+					if expected, err = m.New(func(v interface{}) error {
+						reflect.ValueOf(v).Elem().Set(list)
+						return nil
+					}); err != nil {
+						t.Fatal(err)
+					}
+				}
+				if !reflect.DeepEqual(set.Modules[m.Name], expected) {
+					t.Errorf("%v: test set %d: %v differ from the typed decoding", file, id, m.Key)
+				}
+			}
+		}
+	}
+}
+
+func TestModuleListScalars(t *testing.T) {
+	data := `
+Name: scalars
+Projects:
+  - Name: 3.20
+    Test: C
+    Tags:
+      - Key: enabled
+        Value: yes
+      - Key: mode
+        Value: 0755
+`
+	var set TestSet
+	if err := yaml.Unmarshal([]byte(data), &set); err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Modules[types.MOD_PROJECT]) != 1 {
+		t.Fatalf("expected 1 project test, got %d", len(set.Modules[types.MOD_PROJECT]))
+	}
+	project := set.Modules[types.MOD_PROJECT][0].(*types.ProjectCRUD)
+	if project.Name != "3.20" {
+		t.Errorf("project name is %q, expected 3.20", project.Name)
+	}
+	if project.Tags[0].Value != "yes" || project.Tags[1].Value != "0755" {
+		t.Errorf("tag values are %q and %q, expected yes and 0755", project.Tags[0].Value, project.Tags[1].Value)
+	}
+}
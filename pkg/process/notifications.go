@@ -0,0 +1,305 @@
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	NOTIFY_GENERIC = "generic"
+	NOTIFY_SLACK   = "slack"
+	NOTIFY_TEAMS   = "teams"
+
+	NOTIFY_FINISH_ALWAYS  = "always"
+	NOTIFY_FINISH_FAILURE = "failure"
+	NOTIFY_FINISH_NEVER   = "never"
+
+	NOTIFY_TRIGGER_FINISHED  = "finished"
+	NOTIFY_TRIGGER_THRESHOLD = "threshold"
+	NOTIFY_TRIGGER_LABEL     = "label"
+
+	notificationMaxFailures = 20
+)
+
+// a webhook which is sent a JSON message when the run finishes, when too many tests fail, or when a labelled test fails
+type Notification struct {
+	Name             string            `yaml:"Name"`
+	URL              string            `yaml:"URL"`
+	Template         string            `yaml:"Template"` // generic (default), slack or teams
+	Headers          map[string]string `yaml:"Headers"`
+	OnFinish         string            `yaml:"OnFinish"`         // always, failure (only if tests failed) or never (default)
+	FailureThreshold uint              `yaml:"FailureThreshold"` // notify as soon as this many tests have failed, 0 to disable
+	FailedLabels     []string          `yaml:"FailedLabels"`     // notify when a test with one of these labels fails
+}
+
+func (n Notification) String() string {
+	if n.Name != "" {
+		return n.Name
+	}
+	return n.Template
+}
+
+func (n Notification) Validate() error {
+	if n.URL == "" {
+		return fmt.Errorf("notification %v has no URL", n.String())
+	}
+	switch strings.ToLower(n.Template) {
+	case "", NOTIFY_GENERIC, NOTIFY_SLACK, NOTIFY_TEAMS:
+	default:
+		return fmt.Errorf("notification %v has invalid template %v, options are: generic, slack, teams", n.String(), n.Template)
+	}
+	switch strings.ToLower(n.OnFinish) {
+	case "", NOTIFY_FINISH_ALWAYS, NOTIFY_FINISH_FAILURE, NOTIFY_FINISH_NEVER:
+	default:
+		return fmt.Errorf("notification %v has invalid OnFinish %v, options are: always, failure, never", n.String(), n.OnFinish)
+	}
+	if n.OnFinish == "" && n.FailureThreshold == 0 && len(n.FailedLabels) == 0 {
+		return fmt.Errorf("notification %v is never sent, set OnFinish, FailureThreshold or FailedLabels", n.String())
+	}
+	return nil
+}
+
+// the generic message, which is also the source for the slack and teams templates
+type NotificationMessage struct {
+	Trigger     string                `json:"Trigger"` // finished, threshold or label
+	Title       string                `json:"Title"`
+	Environment string                `json:"Environment"`
+	Target      string                `json:"Target"`
+	Tenant      string                `json:"Tenant"`
+	Tests       int                   `json:"Tests"` // number of tests in the configuration
+	Summary     Counter               `json:"Summary"`
+	Duration    float64               `json:"Duration"` // seconds since the start of the run
+	Failures    []NotificationFailure `json:"Failures"`
+}
+
+type NotificationFailure struct {
+	Set    string   `json:"Set"`
+	Test   string   `json:"Test"`
+	Reason string   `json:"Reason"`
+	Labels []string `json:"Labels,omitempty"`
+}
+
+// Notifier keeps track of the results of a run to send the configured notifications
+type Notifier struct {
+	config *TestConfig
+	logger *logrus.Logger
+	client *http.Client
+	start  time.Time
+
+	lock          sync.Mutex
+	summary       Counter
+	failures      []NotificationFailure
+	thresholdSent []bool
+	wg            sync.WaitGroup
+}
+
+func NewNotifier(Config *TestConfig, logger *logrus.Logger) *Notifier {
+	if len(Config.Notifications) == 0 {
+		return nil
+	}
+	return &Notifier{
+		config:        Config,
+		logger:        logger,
+		client:        &http.Client{Timeout: 30 * time.Second}, // webhooks are not called through the Cx1 proxy
+		start:         time.Now(),
+		thresholdSent: make([]bool, len(Config.Notifications)),
+	}
+}
+
+// ObserveTest sends the threshold and label notifications triggered by a test result
+func (n *Notifier) ObserveTest(result *TestResult) {
+	if n == nil {
+		return
+	}
+	n.lock.Lock()
+	defer n.lock.Unlock()
+
+	switch result.Result {
+	case TST_PASS:
+		n.summary.Pass++
+		return
+	case TST_SKIP:
+		n.summary.Skip++
+		return
+	case TST_SLOW:
+		n.summary.Slow++
+		return
+	}
+
+	n.summary.Fail++
+	failure := NotificationFailure{
+		Set:    result.Name,
+		Test:   fmt.Sprintf("%v %v: %v", result.CRUD, result.Module, result.TestObject),
+		Reason: result.Reason[0],
+		Labels: result.Labels,
+	}
+	n.failures = append(n.failures, failure)
+
+	for id, notification := range n.config.Notifications {
+		if notification.FailureThreshold > 0 && !n.thresholdSent[id] && n.summary.Fail >= notification.FailureThreshold {
+			n.thresholdSent[id] = true
+			n.send(notification, n.message(NOTIFY_TRIGGER_THRESHOLD, fmt.Sprintf("%d tests have failed so far", n.summary.Fail), n.failures))
+		}
+
+		for _, label := range result.Labels {
+			if slices.Contains(notification.FailedLabels, label) {
+				n.send(notification, n.message(NOTIFY_TRIGGER_LABEL, fmt.Sprintf("%v test failed: %v", label, failure.Test), []NotificationFailure{failure}))
+				break
+			}
+		}
+	}
+}
+
+// Finish sends the notifications for the end of the run and waits until all notifications are sent
+func (n *Notifier) Finish() {
+	if n == nil {
+		return
+	}
+	n.lock.Lock()
+	title := fmt.Sprintf("no failures in %d tests", n.summary.Count())
+	if n.summary.Fail > 0 {
+		title = fmt.Sprintf("%d of %d tests failed", n.summary.Fail, n.summary.Count())
+	}
+	for _, notification := range n.config.Notifications {
+		switch strings.ToLower(notification.OnFinish) {
+		case NOTIFY_FINISH_ALWAYS:
+			n.send(notification, n.message(NOTIFY_TRIGGER_FINISHED, title, n.failures))
+		case NOTIFY_FINISH_FAILURE:
+			if n.summary.Fail > 0 {
+				n.send(notification, n.message(NOTIFY_TRIGGER_FINISHED, title, n.failures))
+			}
+		}
+	}
+	n.lock.Unlock()
+
+	n.wg.Wait()
+}
+
+func (n *Notifier) message(trigger, title string, failures []NotificationFailure) NotificationMessage {
+	environment := historyEnvironment(n.config)
+	return NotificationMessage{
+		Trigger:     trigger,
+		Title:       fmt.Sprintf("cx1e2e %v: %v", environment, title),
+		Environment: environment,
+		Target:      n.config.Cx1URL,
+		Tenant:      n.config.Tenant,
+		Tests:       n.config.TestCount,
+		Summary:     n.summary,
+		Duration:    time.Since(n.start).Seconds(),
+		Failures:    slices.Clone(failures),
+	}
+}
+
+// sending is done in the background so that a slow webhook does not delay the tests
+func (n *Notifier) send(notification Notification, message NotificationMessage) {
+	n.wg.Add(1)
+	go func() {
+		defer n.wg.Done()
+		if err := n.post(notification, message); err != nil {
+			n.logger.Errorf("Failed to send %v notification %v: %s", message.Trigger, notification.String(), err)
+		} else {
+			n.logger.Infof("Sent %v notification %v", message.Trigger, notification.String())
+		}
+	}()
+}
+
+func (n *Notifier) post(notification Notification, message NotificationMessage) error {
+	var payload any
+	switch strings.ToLower(notification.Template) {
+	case NOTIFY_SLACK:
+		payload = slackPayload(message)
+	case NOTIFY_TEAMS:
+		payload = teamsPayload(message)
+	default:
+		payload = message
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, notification.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range notification.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %v", resp.Status)
+	}
+	return nil
+}
+
+// the failures as text lines, limited so that the message stays within the webhook size limits
+func (m NotificationMessage) failureLines() []string {
+	lines := []string{}
+	for id, f := range m.Failures {
+		if id == notificationMaxFailures {
+			lines = append(lines, fmt.Sprintf("... and %d more", len(m.Failures)-id))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%v - %v: %v", f.Set, f.Test, f.Reason))
+	}
+	return lines
+}
+
+func (m NotificationMessage) summaryLine() string {
+	return fmt.Sprintf("PASS: %d, FAIL: %d, SKIP: %d, SLOW: %d (%d tests, %.0f seconds)", m.Summary.Pass, m.Summary.Fail, m.Summary.Skip, m.Summary.Slow, m.Tests, m.Duration)
+}
+
+// slack incoming webhook, see https://api.slack.com/messaging/webhooks
+func slackPayload(m NotificationMessage) map[string]any {
+	text := fmt.Sprintf("*%v*\n%v\n%v", m.Title, m.Target, m.summaryLine())
+	for _, line := range m.failureLines() {
+		text += "\n• " + line
+	}
+	return map[string]any{"text": text}
+}
+
+// teams workflow webhook with an adaptive card, see https://learn.microsoft.com/en-us/connectors/teams/
+func teamsPayload(m NotificationMessage) map[string]any {
+	body := []map[string]any{
+		{"type": "TextBlock", "text": m.Title, "weight": "Bolder", "size": "Medium", "wrap": true},
+		{"type": "FactSet", "facts": []map[string]string{
+			{"title": "Target", "value": m.Target},
+			{"title": "Tenant", "value": m.Tenant},
+			{"title": "Results", "value": m.summaryLine()},
+		}},
+	}
+	for _, line := range m.failureLines() {
+		body = append(body, map[string]any{"type": "TextBlock", "text": line, "wrap": true, "color": "Attention"})
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+}
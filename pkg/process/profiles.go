@@ -0,0 +1,115 @@
+package process
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// a named connection to a Cx1 environment, stored in the profiles file so that credentials do not need to be passed on the command-line
+type Profile struct {
+	Name         string `yaml:"Name"`
+	Cx1URL       string `yaml:"Cx1URL"`
+	IAMURL       string `yaml:"IAMURL"`
+	Tenant       string `yaml:"Tenant"`
+	AuthType     string `yaml:"AuthType"` // apikey, oauth, or token - if empty, the first credential set is used
+	APIKey       string `yaml:"APIKey"`
+	ClientID     string `yaml:"ClientID"`
+	ClientSecret string `yaml:"ClientSecret"`
+	AccessToken  string `yaml:"AccessToken"`
+	ProxyURL     string `yaml:"ProxyURL"`
+	NoTLS        bool   `yaml:"NoTLS"`
+	UserAgent    string `yaml:"UserAgent"`
+}
+
+type ProfilesFile struct {
+	Profiles []Profile `yaml:"Profiles"`
+}
+
+func DefaultProfilesPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "cx1e2e", "profiles.yaml")
+}
+
+func LoadProfile(logger *logrus.Logger, profilesPath, name string) (Profile, error) {
+	var profiles ProfilesFile
+
+	if profilesPath == "" {
+		profilesPath = DefaultProfilesPath()
+	}
+
+	info, err := os.Stat(profilesPath)
+	if err != nil {
+		return Profile{}, err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		logger.Warnf("Profiles file %v is accessible by other users (mode %v), consider restricting it with: chmod 600 %v", profilesPath, info.Mode().Perm(), profilesPath)
+	}
+
+	fileBytes, err := os.ReadFile(profilesPath)
+	if err != nil {
+		return Profile{}, err
+	}
+
+	if err = yaml.Unmarshal([]byte(substituteEnv(string(fileBytes))), &profiles); err != nil {
+		return Profile{}, fmt.Errorf("failed to parse profiles file %v: %s", profilesPath, err)
+	}
+
+	for _, p := range profiles.Profiles {
+		if p.Name == name {
+			return p, p.Validate()
+		}
+	}
+
+	return Profile{}, fmt.Errorf("profile %v is not defined in %v", name, profilesPath)
+}
+
+func (p Profile) GetAuthType() string {
+	switch strings.ToLower(p.AuthType) {
+	case "apikey":
+		return "apikey"
+	case "oauth", "client":
+		return "oauth"
+	case "token", "accesstoken", "access-token":
+		return "token"
+	case "":
+		if p.APIKey != "" {
+			return "apikey"
+		} else if p.ClientID != "" {
+			return "oauth"
+		} else if p.AccessToken != "" {
+			return "token"
+		}
+	}
+	return ""
+}
+
+func (p Profile) Validate() error {
+	switch p.GetAuthType() {
+	case "apikey":
+		if p.APIKey == "" {
+			return fmt.Errorf("profile %v uses apikey authentication but has no APIKey", p.Name)
+		}
+	case "oauth":
+		if p.ClientID == "" || p.ClientSecret == "" {
+			return fmt.Errorf("profile %v uses oauth authentication but is missing the ClientID or ClientSecret", p.Name)
+		}
+	case "token":
+		if p.AccessToken == "" {
+			return fmt.Errorf("profile %v uses token authentication but has no AccessToken", p.Name)
+		}
+	default:
+		if p.AuthType != "" {
+			return fmt.Errorf("profile %v has unknown AuthType %v, options are: apikey, oauth, token", p.Name, p.AuthType)
+		}
+	}
+	return nil
+}
@@ -1,9 +1,13 @@
 package process
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"time"
 
@@ -32,10 +36,14 @@ func prepareReportData(tests *[]TestResult, Config *TestConfig, startTime, endTi
 	report.Settings.E2ESuffix = os.Getenv("E2E_RUN_SUFFIX")
 	report.Settings.Version = Config.EnvironmentVersion
 	report.Settings.Threads = threads
+	report.Settings.Environment = Config.Environment
+	report.Summary.Legacy = Config.LegacySummary
 
 	for _, r := range *tests {
 		report.AddTest(&r)
 	}
+	report.SetKeys()
+	report.SLA = report.SLAEntries()
 
 	return report
 }
@@ -44,10 +52,18 @@ func (c *Counter) AddTest(t *TestResult) {
 	switch t.Result {
 	case TST_PASS:
 		c.Pass++
+		if t.Attempts > 1 {
+			c.Flaky++
+		}
 	case TST_FAIL:
 		c.Fail++
 	case TST_SKIP:
 		c.Skip++
+	case TST_SLOW:
+		c.Slow++
+		if t.Attempts > 1 {
+			c.Flaky++
+		}
 	}
 }
 
@@ -65,49 +81,134 @@ func (c *CounterSet) AddTest(t *TestResult) {
 }
 
 func (s *ReportSummary) AddTest(t *TestResult) {
-	switch t.Module {
-	case types.MOD_ACCESS:
-		s.Area.Access.AddTest(t)
-	case types.MOD_APPLICATION:
-		s.Area.Application.AddTest(t)
-	case types.MOD_ANALYTICS:
-		s.Area.Analytics.AddTest(t)
-	case types.MOD_BRANCH:
-		s.Area.Branch.AddTest(t)
-	case types.MOD_CLIENT:
-		s.Area.Client.AddTest(t)
-	case types.MOD_FLAG:
-		s.Area.Flag.AddTest(t)
-	case types.MOD_GROUP:
-		s.Area.Group.AddTest(t)
-	case types.MOD_IMPORT:
-		s.Area.Import.AddTest(t)
-	case types.MOD_PRESET:
-		s.Area.Preset.AddTest(t)
-	case types.MOD_PROJECT:
-		s.Area.Project.AddTest(t)
-	case types.MOD_QUERY:
-		s.Area.Query.AddTest(t)
-	case types.MOD_RESULT:
-		s.Area.Result.AddTest(t)
-	case types.MOD_REPORT:
-		s.Area.Report.AddTest(t)
-	case types.MOD_ROLE:
-		s.Area.Role.AddTest(t)
-	case types.MOD_SCAN:
-		s.Area.Scan.AddTest(t)
-	case types.MOD_USER:
-		s.Area.User.AddTest(t)
+	if s.Modules == nil {
+		s.Modules = map[string]*CounterSet{}
 	}
+	module, ok := s.Modules[t.Module]
+	if !ok {
+		module = &CounterSet{}
+		s.Modules[t.Module] = module
+	}
+	module.AddTest(t)
+	s.Total.AddTest(t)
+}
 
-	switch t.Result {
-	case TST_PASS:
-		s.Total.Pass++
-	case TST_SKIP:
-		s.Total.Skip++
-	case TST_FAIL:
-		s.Total.Fail++
+type ReportArea struct {
+	Module string
+	Label  string
+	Count  *CounterSet
+}
+
+// the label of a module in the report summary, reports may include modules which are not registered in this build
+func ModuleLabel(module string) string {
+	if m, ok := GetModule(module); ok && m.Label != "" {
+		return m.Label
 	}
+	return module
+}
+
+// the summary of each module which has tests, ordered by label
+func (s *ReportSummary) Areas() []ReportArea {
+	areas := []ReportArea{}
+	for module, count := range s.Modules {
+		areas = append(areas, ReportArea{Module: module, Label: ModuleLabel(module), Count: count})
+	}
+	slices.SortFunc(areas, func(a, b ReportArea) int {
+		return strings.Compare(a.Label, b.Label)
+	})
+	return areas
+}
+
+// the fields of the ReportSummary.Area structure of earlier versions, in their order
+var legacyAreas = []struct {
+	Field  string
+	Module string
+}{
+	{"Access", types.MOD_ACCESS},
+	{"Application", types.MOD_APPLICATION},
+	{"Analytics", types.MOD_ANALYTICS},
+	{"Branch", types.MOD_BRANCH},
+	{"Client", types.MOD_CLIENT},
+	{"Flag", types.MOD_FLAG},
+	{"Group", types.MOD_GROUP},
+	{"Import", types.MOD_IMPORT},
+	{"Preset", types.MOD_PRESET},
+	{"Project", types.MOD_PROJECT},
+	{"Query", types.MOD_QUERY},
+	{"Result", types.MOD_RESULT},
+	{"Report", types.MOD_REPORT},
+	{"Role", types.MOD_ROLE},
+	{"Scan", types.MOD_SCAN},
+	{"User", types.MOD_USER},
+}
+
+// the modules are a map, which encoding/json writes in sorted key order
+// with Legacy set, the fixed Area structure of earlier versions is written instead for existing consumers of the JSON report
+func (s ReportSummary) MarshalJSON() ([]byte, error) {
+	type summary ReportSummary
+	if !s.Legacy {
+		if s.Modules == nil {
+			s.Modules = map[string]*CounterSet{}
+		}
+		return json.Marshal(summary(s))
+	}
+
+	var buf bytes.Buffer
+	total, err := json.Marshal(s.Total)
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteString(`{"Total":`)
+	buf.Write(total)
+	buf.WriteString(`,"Area":{`)
+	for id, area := range legacyAreas {
+		count := CounterSet{}
+		if c, ok := s.Modules[area.Module]; ok {
+			count = *c
+		}
+		data, err := json.Marshal(count)
+		if err != nil {
+			return nil, err
+		}
+		if id > 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(fmt.Sprintf("%q:", area.Field))
+		buf.Write(data)
+	}
+	buf.WriteString("}}")
+	return buf.Bytes(), nil
+}
+
+// reads both the current and the legacy summary, so that reports and history of earlier versions can be loaded
+func (s *ReportSummary) UnmarshalJSON(data []byte) error {
+	type summary ReportSummary
+	var decoded struct {
+		summary
+		Area map[string]CounterSet `json:"Area"`
+	}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	*s = ReportSummary(decoded.summary)
+	if s.Modules == nil {
+		s.Modules = map[string]*CounterSet{}
+	}
+	for _, area := range legacyAreas {
+		if count, ok := decoded.Area[area.Field]; ok && count.Count() > 0 {
+			s.Modules[area.Module] = &count
+		}
+	}
+	return nil
+}
+
+func (c *CounterSet) Count() uint {
+	return c.Create.Count() + c.Read.Count() + c.Update.Count() + c.Delete.Count()
+}
+
+func (c *Counter) Count() uint {
+	return c.Pass + c.Fail + c.Skip + c.Slow
 }
 
 func (r *Report) AddTest(t *TestResult) {
@@ -122,8 +223,19 @@ func (r *Report) AddTest(t *TestResult) {
 		Name:       t.Name,
 		Source:     t.TestSource,
 		Test:       fmt.Sprintf("%v %v: %v", t.CRUD, t.Module, t.TestObject),
+		Module:     t.Module,
+		CRUD:       t.CRUD,
+		Object:     t.TestObject,
+		Negative:   t.FailTest,
+		Attempts:   t.Attempts,
 		Duration:   t.Duration,
 		ResultType: t.Result,
+		Thread:     t.Thread,
+		Calls:      t.Calls,
+		TraceID:    t.TraceID,
+		Budget:     t.Budget,
+		Artifacts:  t.Artifacts,
+		log:        t.Log,
 	}
 
 	switch t.Result {
@@ -135,112 +247,98 @@ func (r *Report) AddTest(t *TestResult) {
 	case TST_SKIP:
 		details.Result = fmt.Sprintf("SKIP: %v", t.Reason[0])
 		details.FailOutputs = t.Reason
+	case TST_SLOW:
+		details.Result = fmt.Sprintf("SLOW: %v", t.Reason[0])
+		details.FailOutputs = t.Reason
 	}
 
 	details.ID = t.Id
 	r.Details = append(r.Details, details)
 }
 
+// the key is made from the source file, test set, CRUD operation, module and object, without the run suffix
+// identical tests are numbered in the order they appear in the configuration
+// the source file is relative to the directory of the root configuration, so that keys match across workspaces
+func (r *Report) SetKeys() {
+	root := ""
+	if r.Settings.Config != "" {
+		root = filepath.Dir(r.Settings.Config)
+	}
+	seen := map[string]int{}
+	for id := range r.Details {
+		d := &r.Details[id]
+		key := d.baseKey(r.Settings.E2ESuffix, root)
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%v #%d", key, seen[key])
+		}
+		d.Key = key
+	}
+}
+
+func (d *ReportTestDetails) baseKey(suffix, root string) string {
+	test := d.Test // reports from older versions only have the combined test description
+	if d.Module != "" {
+		test = fmt.Sprintf("%v %v %v", d.CRUD, d.Module, d.Object)
+	}
+	if suffix != "" {
+		test = strings.ReplaceAll(test, suffix, "")
+	}
+	if d.Negative {
+		test += " (negative)"
+	}
+	return fmt.Sprintf("%v|%v|%v", filepath.ToSlash(relativeSource(d.Source, root)), d.Name, test)
+}
+
+func relativeSource(source, root string) string {
+	if root == "" || source == CONFIG_YAML_SOURCE {
+		return source
+	}
+	abs, err := filepath.Abs(source)
+	if err != nil {
+		return source
+	}
+	if rel, err := filepath.Rel(root, abs); err == nil {
+		return rel
+	}
+	return source
+}
+
 func (d ReportTestDetails) String() string {
 	switch d.ResultType {
 	case TST_FAIL:
 		return fmt.Sprintf("FAIL x %v - %v: %v", d.Source, d.Test, strings.Join(d.FailOutputs, ", "))
 	case TST_SKIP:
 		return fmt.Sprintf("SKIP - %v - %v: %v", d.Source, d.Test, strings.Join(d.FailOutputs, ", "))
+	case TST_SLOW:
+		return fmt.Sprintf("SLOW ~ %v - %v: %v", d.Source, d.Test, strings.Join(d.FailOutputs, ", "))
 	}
 	return fmt.Sprintf("PASS   %v - %v", d.Source, d.Test)
 }
 
-func OutputSummaryConsole(reportData *Report, logger *logrus.Logger) {
-	fmt.Println("Test result summary:")
+func OutputSummaryConsole(reportData *Report, out io.Writer) {
+	fmt.Fprintln(out, "Test result summary:")
 	for _, r := range reportData.Details {
-		fmt.Println(r.String())
+		fmt.Fprintln(out, r.String())
 	}
 
-	fmt.Println("")
-	fmt.Printf("Ran %d tests over %v (%d threads)\n", (reportData.Summary.Total.Fail + reportData.Summary.Total.Pass + reportData.Summary.Total.Skip), reportData.Settings.Duration, reportData.Settings.Threads)
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Ran %d tests over %v (%d threads)\n", reportData.Summary.Total.Count(), reportData.Settings.Duration, reportData.Settings.Threads)
 	if reportData.Summary.Total.Fail > 0 {
-		fmt.Printf("FAILED %d tests\n", reportData.Summary.Total.Fail)
+		fmt.Fprintf(out, "FAILED %d tests\n", reportData.Summary.Total.Fail)
 	}
 	if reportData.Summary.Total.Skip > 0 {
-		fmt.Printf("SKIPPED %d tests\n", reportData.Summary.Total.Skip)
+		fmt.Fprintf(out, "SKIPPED %d tests\n", reportData.Summary.Total.Skip)
 	}
-	if reportData.Summary.Total.Pass > 0 {
-		fmt.Printf("PASSED %d tests\n", reportData.Summary.Total.Pass)
-	}
-
-}
-
-func OutputReportHTML(reportName string, reportData *Report, Config *TestConfig) error {
-	report, err := os.Create(reportName)
-	if err != nil {
-		return err
+	if reportData.Summary.Total.Slow > 0 {
+		fmt.Fprintf(out, "SLOW %d tests passed but exceeded their duration budget\n", reportData.Summary.Total.Slow)
 	}
-
-	defer report.Close()
-	_, err = report.WriteString(fmt.Sprintf("<html><head><title>%v tenant %v test - %v</title></head><body>", Config.Cx1URL, Config.Tenant, time.Now().String()))
-	if err != nil {
-		return err
-	}
-
-	report.WriteString("<h2>Settings</h2>")
-	report.WriteString(fmt.Sprintf("Running end to end tests against %v (version: %v)<br>", reportData.Settings.Target, reportData.Settings.Version.String()))
-	report.WriteString(fmt.Sprintf("Authenticated using %v<br>", reportData.Settings.Auth))
-	report.WriteString(fmt.Sprintf("Test set defined in configuration %v<br>", reportData.Settings.Config))
-	report.WriteString(fmt.Sprintf("Test Execution took %v, from %v until %v.<br>", reportData.Settings.Duration, reportData.Settings.StartTime, reportData.Settings.EndTime))
-	report.WriteString(fmt.Sprintf("Tests executed using %d threads.<br>", reportData.Settings.Threads))
-	if os.Getenv("E2E_RUN_SUFFIX") == "" {
-		report.WriteString(fmt.Sprintf("Default object name suffix %%E2E_RUN_SUFFIX%% environment variable is blank. Objects created by cx1e2e will use default names.<br>"))
-	} else {
-		report.WriteString(fmt.Sprintf("Default object name suffix %%E2E_RUN_SUFFIX%% environment variable is set to %v. Objects created by cx1e2e will use this suffix in the name.<br>", os.Getenv("E2E_RUN_SUFFIX")))
-	}
-
-	report.WriteString("<h2>Summary</h2>")
-
-	report.WriteString(fmt.Sprintf("<p>Test status:<br>FAIL: %d<br>SKIP: %d<br>PASS:%d<br></p>", reportData.Summary.Total.Fail, reportData.Summary.Total.Skip, reportData.Summary.Total.Pass))
-
-	report.WriteString("<table border=1 style='border:1px solid black' cellpadding=2 cellspacing=0><tr><th rowspan=2>Area</th><th colspan=3>Create</th><th colspan=3>Read</th><th colspan=3>Update</th><th colspan=3>Delete</th></tr>\n")
-	report.WriteString("<tr><th>Pass</th><th>Fail</th><th>Skip</th><th>Pass</th><th>Fail</th><th>Skip</th><th>Pass</th><th>Fail</th><th>Skip</th><th>Pass</th><th>Fail</th><th>Skip</th></tr>\n")
-	writeCounterSet(report, "Access Assignment", &reportData.Summary.Area.Access)
-	writeCounterSet(report, "Application", &reportData.Summary.Area.Application)
-	writeCounterSet(report, "Analytics", &reportData.Summary.Area.Analytics)
-	writeCounterSet(report, "Branches", &reportData.Summary.Area.Branch)
-	writeCounterSet(report, "Client", &reportData.Summary.Area.Client)
-	writeCounterSet(report, "Flag", &reportData.Summary.Area.Flag)
-	writeCounterSet(report, "Group", &reportData.Summary.Area.Group)
-	writeCounterSet(report, "Import", &reportData.Summary.Area.Import)
-	writeCounterSet(report, "Preset", &reportData.Summary.Area.Preset)
-	writeCounterSet(report, "Project", &reportData.Summary.Area.Project)
-	writeCounterSet(report, "Query", &reportData.Summary.Area.Query)
-	writeCounterSet(report, "Result", &reportData.Summary.Area.Result)
-	writeCounterSet(report, "Report", &reportData.Summary.Area.Report)
-	writeCounterSet(report, "Role", &reportData.Summary.Area.Role)
-	writeCounterSet(report, "Scan", &reportData.Summary.Area.Scan)
-	writeCounterSet(report, "User", &reportData.Summary.Area.User)
-	report.WriteString("</table><br>")
-
-	report.WriteString("<h2>Details</h2>")
-	report.WriteString("<table border=1 style='border:1px solid black' cellpadding=2 cellspacing=0><tr><th>Test Set</th><th>Test</th><th>Duration (sec)</th><th>Result</th></tr>\n")
-
-	for _, t := range reportData.Details {
-		switch t.ResultType {
-		case TST_PASS:
-			report.WriteString(fmt.Sprintf("<tr><td>%v<br>(%v)</td><td>%v</td><td>%.2f</td><td><span style='color:green'>%v</span></td></tr>\n", t.Name, t.Source, t.Test, t.Duration, t.Result))
-		case TST_SKIP:
-			report.WriteString(fmt.Sprintf("<tr><td>%v<br>(%v)</td><td>%v</td><td>%.2f</td><td><span style='color:orange'>%v</span></td></tr>\n", t.Name, t.Source, t.Test, t.Duration, t.Result))
-		case TST_FAIL:
-			report.WriteString(fmt.Sprintf("<tr><td>%v<br>(%v)</td><td>%v</td><td>%.2f</td><td><span style='color:red'>%v\n%v</span></td></tr>\n", t.Name, t.Source, t.Test, t.Duration, t.Result, strings.Join(t.FailOutputs[1:], "<br>\n")))
-		}
+	if reportData.Summary.Total.Pass > 0 {
+		fmt.Fprintf(out, "PASSED %d tests\n", reportData.Summary.Total.Pass)
 	}
-
-	report.WriteString("</table>\n")
-
-	_, err = report.WriteString("</body></html>")
-	if err != nil {
-		return err
+	if reportData.Summary.Total.Flaky > 0 {
+		fmt.Fprintf(out, "FLAKY %d tests passed after being retried\n", reportData.Summary.Total.Flaky)
 	}
-
-	return report.Sync()
 }
 
 func OutputReportJSON(reportName string, reportData *Report) error {
@@ -263,72 +361,128 @@ func OutputReportJSON(reportName string, reportData *Report) error {
 	return report.Sync()
 }
 
-func GenerateReport(tests *[]TestResult, logger *logrus.Logger, Config *TestConfig, startTime, endTime time.Time, threads int) (uint, error) {
+func GenerateReport(tests *[]TestResult, logger *logrus.Logger, Config *TestConfig, startTime, endTime time.Time, threads int) (Report, error) {
 	reportData := prepareReportData(tests, Config, startTime, endTime, threads)
-	OutputSummaryConsole(&reportData, logger)
+	WriteReport(&reportData, logger, Config)
+
+	//status := float32(reportData.Summary.Total.Pass) / float32(reportData.Summary.Total.Skip+reportData.Summary.Total.Fail+reportData.Summary.Total.Pass)
+
+	return reportData, nil
+}
+
+// WriteReport writes the HAR files, artifacts and log excerpts of the tests, prints the summary and writes the configured report files
+func WriteReport(reportData *Report, logger *logrus.Logger, Config *TestConfig) {
+	WriteHARFiles(reportData, Config, logger)
+	WriteArtifacts(reportData, Config, logger)
+	WriteLogExcerpts(reportData, Config, logger)
+	OutputSummaryConsole(reportData, Config.ConsoleWriter())
+	var inline io.Writer
+	if Config.InlineReport {
+		inline = Config.ConsoleWriter()
+	}
+	OutputReports(reportData, Config.ReportType, Config.ReportName, inline, logger)
+}
+
+// the report formats which can be requested through --report-type, comma-separated
+var ReportTypes = []string{"html", "json", "junit", "markdown"}
+
+func ValidReportType(reportType string) bool {
+	for _, t := range strings.Split(reportType, ",") {
+		if !slices.Contains(ReportTypes, strings.TrimSpace(t)) {
+			return false
+		}
+	}
+	return true
+}
 
-	if strings.Contains(Config.ReportType, "html") {
-		filename := fmt.Sprintf("%v.html", Config.ReportName)
-		err := OutputReportHTML(filename, &reportData, Config)
+// OutputReports writes the report in each of the requested formats (comma-separated) to reportName with the format's file extension,
+// and prints their contents to inline if it is not nil
+func OutputReports(reportData *Report, reportType, reportName string, inline io.Writer, logger *logrus.Logger) {
+	if strings.Contains(reportType, "html") {
+		filename := fmt.Sprintf("%v.html", reportName)
+		err := OutputReportHTML(filename, reportData)
 		if err != nil {
-			logger.Errorf("Failed to write HTML report to %v.html: %s", Config.ReportName, err)
-		} else if Config.InlineReport {
-			data, err := os.ReadFile(filename)
-			if err != nil {
-				logger.Errorf("Failed to read report %v for inline-report output: %s", filename, err)
-			} else {
-				logger.Infof("Printing report %v inline:", filename)
-				fmt.Println(string(data))
-			}
+			logger.Errorf("Failed to write HTML report to %v.html: %s", reportName, err)
+		} else if inline != nil {
+			outputInline(filename, inline, logger)
 		}
 	}
 
-	if strings.Contains(Config.ReportType, "json") {
-		filename := fmt.Sprintf("%v.json", Config.ReportName)
-		err := OutputReportJSON(filename, &reportData)
+	if strings.Contains(reportType, "json") {
+		filename := fmt.Sprintf("%v.json", reportName)
+		err := OutputReportJSON(filename, reportData)
 		if err != nil {
-			logger.Errorf("Failed to write JSON report to %v.json: %s", Config.ReportName, err)
-		} else if Config.InlineReport {
-			data, err := os.ReadFile(filename)
-			if err != nil {
-				logger.Errorf("Failed to read report %v for inline-report output: %s", filename, err)
-			} else {
-				logger.Infof("Printing report %v inline:", filename)
-				fmt.Println(string(data))
-			}
+			logger.Errorf("Failed to write JSON report to %v.json: %s", reportName, err)
+		} else if inline != nil {
+			outputInline(filename, inline, logger)
 		}
 	}
 
-	//status := float32(reportData.Summary.Total.Pass) / float32(reportData.Summary.Total.Skip+reportData.Summary.Total.Fail+reportData.Summary.Total.Pass)
+	if strings.Contains(reportType, "markdown") {
+		filename := fmt.Sprintf("%v.md", reportName)
+		err := OutputReportMarkdown(filename, reportData)
+		if err != nil {
+			logger.Errorf("Failed to write markdown report to %v.md: %s", reportName, err)
+		} else if inline != nil {
+			outputInline(filename, inline, logger)
+		}
+	}
 
-	return reportData.Summary.Total.Fail, nil
+	if strings.Contains(reportType, "junit") {
+		filename := fmt.Sprintf("%v.xml", reportName)
+		err := OutputReportJUnit(filename, reportData)
+		if err != nil {
+			logger.Errorf("Failed to write JUnit report to %v.xml: %s", reportName, err)
+		} else if inline != nil {
+			outputInline(filename, inline, logger)
+		}
+	}
 }
 
-func writeCell(report *os.File, count uint, good bool) {
-	if count == 0 {
-		report.WriteString("<td>&nbsp;</td>")
-	} else if good {
-		report.WriteString(fmt.Sprintf("<td style='color:green;text-align:center;'>%d</td>", count))
+func outputInline(filename string, out io.Writer, logger *logrus.Logger) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		logger.Errorf("Failed to read report %v for inline-report output: %s", filename, err)
 	} else {
-		report.WriteString(fmt.Sprintf("<td style='color:red;text-align:center;'>%d</td>", count))
+		logger.Infof("Printing report %v inline:", filename)
+		fmt.Fprintln(out, string(data))
+	}
+}
+
+// LoadReportJSON reads a report previously written by OutputReportJSON
+func LoadReportJSON(reportName string) (Report, error) {
+	var report Report
+
+	data, err := os.ReadFile(reportName)
+	if err != nil {
+		return report, err
 	}
+
+	if err = json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("failed to parse report %v: %s", reportName, err)
+	}
+
+	report.restoreResultTypes()
+	return report, nil
 }
 
-func writeCounterSet(report *os.File, module string, count *CounterSet) {
-	report.WriteString(fmt.Sprintf("<tr><td>%v</td>", module))
-
-	writeCell(report, count.Create.Pass, true)
-	writeCell(report, count.Create.Fail, false)
-	writeCell(report, count.Create.Skip, false)
-	writeCell(report, count.Read.Pass, true)
-	writeCell(report, count.Read.Fail, false)
-	writeCell(report, count.Read.Skip, false)
-	writeCell(report, count.Update.Pass, true)
-	writeCell(report, count.Update.Fail, false)
-	writeCell(report, count.Update.Skip, false)
-	writeCell(report, count.Delete.Pass, true)
-	writeCell(report, count.Delete.Fail, false)
-	writeCell(report, count.Delete.Skip, false)
-
-	report.WriteString("</tr>\n")
+// the result type is not part of the JSON output, and reports from older versions have no test keys
+func (r *Report) restoreResultTypes() {
+	if len(r.Details) > 0 && r.Details[0].Key == "" {
+		r.SetKeys()
+	}
+
+	for id := range r.Details {
+		d := &r.Details[id]
+		switch {
+		case strings.HasPrefix(d.Result, "PASS"):
+			d.ResultType = TST_PASS
+		case strings.HasPrefix(d.Result, "FAIL"):
+			d.ResultType = TST_FAIL
+		case strings.HasPrefix(d.Result, "SLOW"):
+			d.ResultType = TST_SLOW
+		default:
+			d.ResultType = TST_SKIP
+		}
+	}
 }
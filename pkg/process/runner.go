@@ -1,6 +1,7 @@
 package process
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"slices"
@@ -10,12 +11,16 @@ import (
 	"github.com/cxpsemea/Cx1ClientGo"
 	"github.com/cxpsemea/cx1e2e/pkg/types"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
 	TST_FAIL = 0
 	TST_PASS = 1
 	TST_SKIP = 2
+	TST_SLOW = 3 // passed, but took longer than its duration budget
 )
 
 type TestRunner interface {
@@ -32,7 +37,12 @@ type TestRunner interface {
 	GetVersion() types.ProductVersion
 	GetVersionStr() string
 	GetCurrentThread() int
+	GetLabels() []string
+	GetBudget(CRUD string) types.DurationBudget
 	OnFail() types.FailAction
+	SetSource(source string, thread uint)
+	SetID(id uint)
+	SetActiveThread(thread int)
 
 	RunCreate(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, Engines *types.EnabledEngines) error
 	RunRead(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, Engines *types.EnabledEngines) error
@@ -49,10 +59,20 @@ func MakeResult(test TestRunner) TestResult {
 		Id:         test.GetID(),
 		TestObject: test.String(),
 		TestSource: test.GetSource(),
+		Thread:     test.GetCurrentThread(),
+		Labels:     test.GetLabels(),
 	}
 }
 
-func RunTests(cx1client *Cx1ClientGo.Cx1Client, logger *logrus.Logger, Config *TestConfig, threads int) uint {
+// RunTestsReport runs the tests and generates the configured reports, returning the report data
+func RunTestsReport(cx1client *Cx1ClientGo.Cx1Client, logger *logrus.Logger, Config *TestConfig, threads int) Report {
+	_, report := runTests(context.Background(), cx1client, logger, Config, threads, true)
+	return report
+}
+
+// runs the tests until they are finished or the context is cancelled, which stops the threads from picking up further test sets
+// with writeOutput, the console summary, the report files, the metrics file and the history are written as configured
+func runTests(ctx context.Context, cx1client *Cx1ClientGo.Cx1Client, logger *logrus.Logger, Config *TestConfig, threads int, writeOutput bool) ([]TestResult, Report) {
 	startTime := time.Now()
 	all_results := []TestResult{}
 	dir := NewDirector(Config)
@@ -62,6 +82,25 @@ func RunTests(cx1client *Cx1ClientGo.Cx1Client, logger *logrus.Logger, Config *T
 		threads = 1
 	}
 
+	var span trace.Span
+	dir.Context, span = Config.Tracer.Start(ctx, "cx1e2e run", trace.SpanKindInternal,
+		attribute.String("cx1e2e.config", Config.ConfigPath),
+		attribute.String("cx1e2e.environment", historyEnvironment(Config)),
+		attribute.Int("cx1e2e.threads", threads),
+		attribute.Int("cx1e2e.tests", Config.TestCount),
+	)
+	Config.Notifier = NewNotifier(Config, logger)
+	if Config.LogDir != "" {
+		logs, err := NewTestLogs(Config.LogDir, logger)
+		if err != nil {
+			logger.Errorf("Failed to create log directory %v: %s", Config.LogDir, err)
+		} else {
+			Config.Logs = logs
+			defer Config.Logs.Close()
+		}
+	}
+	Config.Events.Emit(Config, Event{Event: EVT_RUN_STARTED, Config: Config.ConfigPath, Tests: Config.TestCount, Threads: threads})
+
 	out_channels := make(chan *[]TestResult, threads)
 	for i := range threads {
 		go NewRunner(i+1, &dir, cx1client, logger, Config, out_channels)
@@ -76,9 +115,12 @@ func RunTests(cx1client *Cx1ClientGo.Cx1Client, logger *logrus.Logger, Config *T
 	endTime := time.Now()
 
 	// tests are finished running, so do some cleanup
-	if types.ASM != nil {
-		types.ASM.Clear(cx1client, logger)
+	if len(dir.Sessions.Sessions) > 0 {
+		Config.Events.Emit(Config, Event{Event: EVT_CLEANUP, Action: "clear audit sessions"})
 	}
+	dir.Sessions.Clear(cx1client, logger)
+	span.End()
+	Config.Tracer.Flush()
 
 	// the test-results may be unsorted due to threading, sort them
 	if threads > 1 {
@@ -87,17 +129,41 @@ func RunTests(cx1client *Cx1ClientGo.Cx1Client, logger *logrus.Logger, Config *T
 		})
 	}
 
-	status, err := GenerateReport(&all_results, logger, Config, startTime, endTime, threads)
-	if err != nil {
-		logger.Errorf("Failed to generate the report: %s", err)
+	report := prepareReportData(&all_results, Config, startTime, endTime, threads)
+	if writeOutput {
+		WriteReport(&report, logger, Config)
+	}
+
+	Config.Notifier.Finish()
+	Config.Metrics.ObserveRun(Config, endTime)
+	if writeOutput && Config.MetricsFile != "" {
+		if err := Config.Metrics.WriteFile(Config.MetricsFile); err != nil {
+			logger.Errorf("Failed to write metrics to %v: %s", Config.MetricsFile, err)
+		}
 	}
+
+	if writeOutput && Config.HistoryDir != "" {
+		if err := AppendHistory(Config.HistoryDir, Config, &report, endTime); err != nil {
+			logger.Errorf("Failed to store the results in history directory %v: %s", Config.HistoryDir, err)
+		}
+	}
+	Config.Events.Emit(Config, Event{Event: EVT_RUN_FINISHED, Duration: endTime.Sub(startTime).Seconds(), Summary: &report.Summary.Total})
 	logger.Infof("Test complete")
 
-	return status
+	return all_results, report
 }
 
-func (t *TestSet) RunTests(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, Config *TestConfig, testSetFail error) []TestResult {
+func (t *TestSet) RunTests(ctx context.Context, cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, Config *TestConfig, testSetFail error) []TestResult {
 	logger.Tracef("Running test set: %v [%v]", t.Name, t.TestSource)
+	ctx, span := Config.Tracer.Start(ctx, fmt.Sprintf("TestSet %v", t.Name), trace.SpanKindInternal,
+		attribute.String("cx1e2e.set", t.Name),
+		attribute.String("cx1e2e.source", t.TestSource),
+		attribute.Int("cx1e2e.thread", t.ActiveThread),
+	)
+	defer span.End()
+	parent := logger.GetContext(nil)
+	logger.SetContext(ctx)
+	defer logger.SetContext(parent)
 
 	var err error = testSetFail
 	var testClient *Cx1ClientGo.Cx1Client
@@ -134,197 +200,71 @@ func (t *TestSet) RunTests(cx1client *Cx1ClientGo.Cx1Client, logger *types.Threa
 
 	if len(t.SubTests) > 0 {
 		for id := range t.SubTests {
-			results = t.SubTests[id].RunTests(testClient, logger, Config, err)
+			results = t.SubTests[id].RunTests(ctx, testClient, logger, Config, err)
 			all_results = append(all_results, results...)
 		}
 	} else {
-		results, err = t.Run(testClient, logger, types.OP_CREATE, Config, err)
+		results, err = t.Run(ctx, testClient, logger, types.OP_CREATE, Config, err)
 		all_results = append(all_results, results...)
-		results, err = t.Run(testClient, logger, types.OP_READ, Config, err)
+		results, err = t.Run(ctx, testClient, logger, types.OP_READ, Config, err)
 		all_results = append(all_results, results...)
-		results, err = t.Run(testClient, logger, types.OP_UPDATE, Config, err)
+		results, err = t.Run(ctx, testClient, logger, types.OP_UPDATE, Config, err)
 		all_results = append(all_results, results...)
-		results, _ = t.Run(testClient, logger, types.OP_DELETE, Config, err)
+		results, _ = t.Run(ctx, testClient, logger, types.OP_DELETE, Config, err)
 		all_results = append(all_results, results...)
 	}
 
+	failed := 0
+	for _, r := range all_results {
+		if r.Result == TST_FAIL {
+			failed++
+		}
+	}
+	if failed > 0 {
+		span.SetStatus(codes.Error, fmt.Sprintf("%d tests failed", failed))
+	}
+
 	return all_results
 }
 
-func (t *TestSet) Run(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, CRUD string, Config *TestConfig, TestSetFail error) ([]TestResult, error) {
+func (t *TestSet) Run(ctx context.Context, cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, CRUD string, Config *TestConfig, TestSetFail error) ([]TestResult, error) {
 	results := []TestResult{}
 	var TestSetFailError error
 	TestSetFailError = TestSetFail
 
-	// for CRU operations, follow this order, but for Delete do the reverse
-	if CRUD != types.OP_DELETE {
-		for id := range t.Flags {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Flags[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Analytics {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Analytics[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Imports {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Imports[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Groups {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Groups[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Applications {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Applications[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Projects {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Projects[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Roles {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Roles[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Users {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Users[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Clients {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Clients[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.AccessAssignments {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.AccessAssignments[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Queries {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Queries[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Presets {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Presets[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Scans {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Scans[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Branches {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Branches[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Results {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Results[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Reports {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Reports[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-	} else { // in reverse order for DELETE
-		for id := range t.Scans {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Scans[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Presets {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Presets[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Queries {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Queries[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.AccessAssignments {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.AccessAssignments[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Clients {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Clients[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Users {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Users[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Roles {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Roles[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Projects {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Projects[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Applications {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Applications[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
-		}
-		for id := range t.Groups {
-			err := RunTest(cx1client, logger, CRUD, t.Name, &(t.Groups[id]), &results, Config, TestSetFailError)
-			if err != nil && TestSetFailError == nil {
-				TestSetFailError = err
-			}
+	// for CRU operations the modules run in order of priority, for Delete in order of delete priority
+	for _, test := range t.GetTests(CRUD) {
+		err := RunTest(ctx, cx1client, logger, CRUD, t.Name, test, &results, Config, TestSetFailError)
+		if err != nil && TestSetFailError == nil {
+			TestSetFailError = err
 		}
 	}
 
 	return results, TestSetFailError
 }
 
-func RunTest(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, CRUD, testName string, test TestRunner, results *[]TestResult, Config *TestConfig, failSet error) error {
+func RunTest(ctx context.Context, cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, CRUD, testName string, test TestRunner, results *[]TestResult, Config *TestConfig, failSet error) error {
 	if test.IsType(CRUD) {
 		var result TestResult
 		failAction := test.OnFail()
 
+		logger.SetTest(&types.TestContext{ID: test.GetID(), Set: testName, Module: test.GetModule(), CRUD: CRUD, Object: test.String()})
+		defer logger.SetTest(nil)
+		// the excerpt includes the skip or prerequisite failure, and the retries of the test
+		Config.Logs.StartTest(logger.Thread)
+
+		ctx, span := Config.Tracer.Start(ctx, fmt.Sprintf("%v %v", CRUD, test.GetModule()), trace.SpanKindInternal,
+			attribute.Int("cx1e2e.test.id", int(test.GetID())),
+			attribute.String("cx1e2e.set", testName),
+			attribute.String("cx1e2e.module", test.GetModule()),
+			attribute.String("cx1e2e.crud", CRUD),
+			attribute.String("cx1e2e.object", test.String()),
+			attribute.String("cx1e2e.source", test.GetSource()),
+			attribute.Bool("cx1e2e.negative", test.IsNegative()),
+		)
+		parent := logger.GetContext(nil)
+		logger.SetContext(ctx)
+
 		if failSet != nil {
 			result = MakeResult(test)
 			result.CRUD = CRUD
@@ -354,22 +294,61 @@ func RunTest(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, CRUD,
 				result.Result = TST_SKIP
 				logger.Warnf("Test for %v %v will be skipped. Reason: %s", CRUD, test.String(), err)
 			} else { // test can run
+				Config.Events.Emit(Config, testEvent(EVT_TEST_STARTED, testName, CRUD, test))
+				Config.Hooks.TestStarted(Config, testEvent(EVT_TEST_STARTED, testName, CRUD, test))
 				result = Run(cx1client, logger, CRUD, testName, test, Config)
+				result.Attempts = 1
 				if failAction.RetryCount > 0 && result.Result == TST_FAIL {
 					for count := 1; count <= (int)(failAction.RetryCount) && result.Result == TST_FAIL; count++ {
 						logger.Infof("Test for %v %v failed: %v, waiting %d seconds for retry %d of %d", CRUD, test.String(), result.Reason[0], failAction.RetryDelay, count, failAction.RetryCount)
+						event := testEvent(EVT_TEST_RETRY, testName, CRUD, test)
+						event.Reason = result.Reason
+						event.Retry = uint(count)
+						event.MaxRetries = failAction.RetryCount
+						event.RetryDelay = failAction.RetryDelay
+						Config.Events.Emit(Config, event)
 						time.Sleep(time.Duration(failAction.RetryDelay) * time.Second)
+						Config.Metrics.ObserveRetry(Config, test.GetModule(), CRUD)
 						result = Run(cx1client, logger, CRUD, testName, test, Config)
+						result.Attempts = uint(count + 1)
 					}
 
 					if result.Result == TST_FAIL {
 						result.Reason = append(result.Reason, fmt.Sprintf(" (with %d retries)", failAction.RetryCount))
 					}
 				}
+				result.CheckBudget(Config.GetBudget(test, CRUD))
+				// a strict budget can fail a test which passed, so the traffic is only dropped based on the final result
+				if Config.HAR == HAR_FAILURES && result.Result != TST_FAIL {
+					for id := range result.Calls {
+						result.Calls[id].Exchange = nil
+					}
+				}
 			}
 		}
 
+		result.TraceID = TraceID(span)
+		span.SetAttributes(attribute.String("cx1e2e.result", ResultString(result.Result)))
+		switch result.Result {
+		case TST_PASS, TST_SLOW:
+			span.SetStatus(codes.Ok, "")
+		case TST_FAIL:
+			span.SetStatus(codes.Error, result.Reason[0])
+		}
+		span.End()
+		logger.SetContext(parent)
+
 		LogResult(logger, result)
+		result.Log = Config.Logs.FinishTest(logger.Thread, result.Result == TST_FAIL)
+		Config.Metrics.ObserveTest(Config, test, &result)
+		Config.Notifier.ObserveTest(&result)
+		event := testEvent(EVT_TEST_FINISHED, testName, CRUD, test)
+		event.Result = ResultString(result.Result)
+		event.Reason = result.Reason
+		event.Duration = result.Duration
+		event.TraceID = result.TraceID
+		Config.Events.Emit(Config, event)
+		Config.Hooks.TestFinished(result)
 		*results = append(*results, result)
 
 		if result.Result == TST_FAIL {
@@ -415,6 +394,7 @@ func Run(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, CRUD, tes
 		return result
 	}
 	start := time.Now().UnixNano()
+	Config.Telemetry.StartTest(logger)
 
 	switch CRUD {
 	case types.OP_CREATE:
@@ -429,6 +409,8 @@ func Run(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, CRUD, tes
 
 	duration := float64(time.Now().UnixNano()-start) / float64(time.Second)
 	result.Duration = duration
+	result.Calls = Config.Telemetry.StopTest(logger)
+	result.Artifacts = logger.Artifacts.Take()
 	if err != nil {
 		if test.IsNegative() { // negative test with error = pass
 			result.Result = TST_PASS
@@ -450,9 +432,30 @@ func Run(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, CRUD, tes
 		logger.Tracef("Test did not pass, but has no reason for it: #%d - %v %v %v: %v, failtest: %v [%v]", result.Id, result.CRUD, result.Module, result.TestObject, result.Name, result.FailTest, result.TestSource)
 		result.Reason = []string{"unknown error"}
 	}
+
 	return result
 }
 
+// CheckBudget marks a passing test which took longer than its MaxDuration as SLOW, or as FAIL when the budget is strict
+func (r *TestResult) CheckBudget(budget types.DurationBudget) {
+	if !budget.IsSet() {
+		return
+	}
+	r.Budget = &budget
+
+	if r.Result != TST_PASS || budget.Check(r.Duration) != "SLOW" {
+		return
+	}
+
+	reason := fmt.Sprintf("took %.3fs, longer than the maximum duration of %vs", r.Duration, budget.MaxDuration)
+	if budget.StrictDuration {
+		r.Result = TST_FAIL
+	} else {
+		r.Result = TST_SLOW
+	}
+	r.Reason = []string{reason}
+}
+
 func CheckFlags(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, test TestRunner) bool {
 	for _, flag := range test.GetFlags() {
 		negative := false
@@ -577,7 +580,13 @@ func LogResult(logger *types.ThreadLogger, result TestResult) {
 	case TST_SKIP:
 		logger.Warnf("SKIP [%.3fs]: %v %v %v '%v' (%v) [%v]", result.Duration, result.CRUD, result.Module, testType, result.Name, result.TestObject, result.TestSource)
 		logger.Warnf("Skip reason: %v", result.Reason)
+	case TST_SLOW:
+		logger.Warnf("SLOW [%.3fs]: %v %v %v '%v' (%v) [%v]", result.Duration, result.CRUD, result.Module, testType, result.Name, result.TestObject, result.TestSource)
+		logger.Warnf("Slow reason: %v", result.Reason[0])
 	case TST_PASS:
+		if result.Budget != nil && result.Budget.Check(result.Duration) == "WARN" {
+			logger.Warnf("Test took %.3fs, longer than the warning duration of %vs", result.Duration, result.Budget.WarnDuration)
+		}
 		if result.Attempts > 1 {
 			logger.Infof("PASS [%.3fs]: %v %v %v '%v' (%v) [%v] - took %d attempts", result.Duration, result.CRUD, result.Module, testType, result.Name, result.TestObject, result.TestSource, result.Attempts)
 		} else {
@@ -599,7 +608,7 @@ func (t TestSet) OtherUser() bool {
 }
 
 func (t TestSet) GetOtherClient(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, config *TestConfig) (*Cx1ClientGo.Cx1Client, error) {
-	httpClient, err := config.CreateHTTPClient(logger.GetLogger())
+	httpClient, err := config.CreateThreadHTTPClient(logger)
 	if err != nil {
 		return nil, err
 	}
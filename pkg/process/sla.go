@@ -0,0 +1,77 @@
+package process
+
+import (
+	"slices"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+)
+
+// the duration of a test compared to its budget
+type SLAEntry struct {
+	Key      string               `json:"Key"`
+	Name     string               `json:"Name"`
+	Test     string               `json:"Test"`
+	Module   string               `json:"Module"`
+	CRUD     string               `json:"CRUD"`
+	Duration float64              `json:"Duration"`
+	Budget   types.DurationBudget `json:"Budget"`
+	Status   string               `json:"Status"` // OK, WARN, SLOW or FAIL (a strict budget was exceeded)
+	Usage    float64              `json:"Usage"`  // duration as a percentage of the MaxDuration, or of the WarnDuration without a MaxDuration
+}
+
+// SLAEntries compares the duration of each test with a budget to the budget, ordered by usage of the budget
+// skipped tests and tests which failed for other reasons than their duration are left out
+func (r *Report) SLAEntries() []SLAEntry {
+	entries := []SLAEntry{}
+	for _, d := range r.Details {
+		if d.Budget == nil || d.ResultType == TST_SKIP {
+			continue
+		}
+
+		status := d.Budget.Check(d.Duration)
+		if d.ResultType == TST_FAIL {
+			if status != "SLOW" || !d.Budget.StrictDuration {
+				continue
+			}
+			status = "FAIL"
+		}
+
+		limit := d.Budget.MaxDuration
+		if limit == 0 {
+			limit = d.Budget.WarnDuration
+		}
+
+		entries = append(entries, SLAEntry{
+			Key:      d.Key,
+			Name:     d.Name,
+			Test:     d.TestName(),
+			Module:   d.Module,
+			CRUD:     d.CRUD,
+			Duration: d.Duration,
+			Budget:   *d.Budget,
+			Status:   status,
+			Usage:    d.Duration / limit * 100,
+		})
+	}
+
+	slices.SortStableFunc(entries, func(a, b SLAEntry) int {
+		if a.Usage > b.Usage {
+			return -1
+		} else if a.Usage < b.Usage {
+			return 1
+		}
+		return 0
+	})
+	return entries
+}
+
+// the number of tests which exceeded their warning or maximum duration
+func (r *Report) SLAViolations() int {
+	count := 0
+	for _, e := range r.SLA {
+		if e.Status != "OK" {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,271 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+)
+
+// a single request made by Cx1ClientGo while running a test
+type APICall struct {
+	Method        string  `json:"Method"`
+	Path          string  `json:"Path"` // with object IDs replaced by {id}, eg: /api/projects/{id}
+	Status        int     `json:"Status"`
+	RequestBytes  int64   `json:"RequestBytes"`
+	ResponseBytes int64   `json:"ResponseBytes"`
+	Latency       float64 `json:"Latency"` // seconds until the response headers were received
+	Error         string  `json:"Error,omitempty"`
+	Snippet       string  `json:"Snippet,omitempty"` // start of the response body for non-2xx responses
+
+	Exchange *HAREntry `json:"-"` // the full request and response, when capturing HAR files
+}
+
+func (c *APICall) IsError() bool {
+	return c.Error != "" || c.Status < 200 || c.Status >= 300
+}
+
+func (c *APICall) String() string {
+	if c.Error != "" {
+		return fmt.Sprintf("%v %v: %v", c.Method, c.Path, c.Error)
+	}
+	return fmt.Sprintf("%v %v: %d", c.Method, c.Path, c.Status)
+}
+
+const apiCallSnippetSize = 512
+
+// APITelemetry records the requests made by each test
+// Cx1ClientGo does not pass a context with its requests, so each thread has its own client (see TestConfig.NewClient)
+// whose transport attributes the requests to the test running on that thread
+type APITelemetry struct {
+	CaptureHAR bool
+
+	lock   sync.Mutex
+	active map[*types.ThreadLogger]*[]*APICall
+}
+
+func NewAPITelemetry() *APITelemetry {
+	return &APITelemetry{active: map[*types.ThreadLogger]*[]*APICall{}}
+}
+
+// StartTest begins recording the requests made by the client of the thread
+func (t *APITelemetry) StartTest(thread *types.ThreadLogger) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.active[thread] = &[]*APICall{}
+}
+
+// StopTest returns the requests made by the client of the thread since StartTest
+func (t *APITelemetry) StopTest(thread *types.ThreadLogger) []APICall {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	calls, ok := t.active[thread]
+	if !ok {
+		return nil
+	}
+	delete(t.active, thread)
+
+	list := make([]APICall, len(*calls))
+	for i, c := range *calls {
+		list[i] = *c
+	}
+	return list
+}
+
+// adds the call to the test running on the thread, returns false if no test is running
+func (t *APITelemetry) record(thread *types.ThreadLogger, call *APICall) bool {
+	if thread == nil {
+		return false
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	calls, ok := t.active[thread]
+	if ok {
+		*calls = append(*calls, call)
+	}
+	return ok
+}
+
+// Transport wraps the transport of an http.Client so that its requests are recorded for the tests of the thread,
+// the requests of a client without a thread (nil) are not recorded
+func (t *APITelemetry) Transport(base http.RoundTripper, thread *types.ThreadLogger) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &telemetryTransport{base: base, telemetry: t, thread: thread}
+}
+
+type telemetryTransport struct {
+	base      http.RoundTripper
+	telemetry *APITelemetry
+	thread    *types.ThreadLogger
+}
+
+func (t *telemetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	call := &APICall{
+		Method:       req.Method,
+		Path:         TemplatePath(req.URL.Path),
+		RequestBytes: max(req.ContentLength, 0),
+	}
+	recorded := t.telemetry.record(t.thread, call)
+
+	start := time.Now()
+	// the bodies are only kept for requests made by a test, and dropped once the test passes unless all tests are captured
+	if recorded && t.telemetry.CaptureHAR {
+		call.Exchange = &HAREntry{StartedDateTime: start, Request: newHARRequest(req)}
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	call.Latency = time.Since(start).Seconds()
+	if call.Exchange != nil {
+		call.Exchange.Time = call.Latency * 1000
+		call.Exchange.Timings.Wait = call.Exchange.Time
+	}
+	if err != nil {
+		call.Error = err.Error()
+		if call.Exchange != nil {
+			call.Exchange.Comment = call.Error
+			call.Exchange.Response = HARResponse{Cookies: []HARNameValue{}, Headers: []HARNameValue{}, HeadersSize: -1, BodySize: -1}
+		}
+		return resp, err
+	}
+
+	call.Status = resp.StatusCode
+	if call.Exchange != nil {
+		var text string
+		call.Exchange.Response, text = newHARResponse(resp)
+		if call.IsError() {
+			call.Snippet = redactBody(resp.Header.Get("Content-Type"), text[:min(len(text), apiCallSnippetSize)])
+		}
+	} else if call.IsError() && resp.Body != nil {
+		snippet := make([]byte, apiCallSnippetSize)
+		n, _ := io.ReadFull(resp.Body, snippet)
+		call.Snippet = redactBody(resp.Header.Get("Content-Type"), string(snippet[:n]))
+		resp.Body = &readCloser{io.MultiReader(bytes.NewReader(snippet[:n]), resp.Body), resp.Body}
+	}
+	if resp.Body != nil {
+		resp.Body = &countingBody{ReadCloser: resp.Body, call: call, lock: &t.telemetry.lock}
+	}
+
+	return resp, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type countingBody struct {
+	io.ReadCloser
+	call *APICall
+	lock *sync.Mutex
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.lock.Lock()
+	b.call.ResponseBytes += int64(n)
+	b.lock.Unlock()
+	return n, err
+}
+
+var (
+	pathUUID    = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	pathNumeric = regexp.MustCompile(`^[0-9]+$`)
+	pathHex     = regexp.MustCompile(`^[0-9a-fA-F]{16,}$`)
+)
+
+// TemplatePath replaces the object IDs in a request path so that requests to the same endpoint can be grouped
+func TemplatePath(path string) string {
+	parts := strings.Split(path, "/")
+	for id, part := range parts {
+		if pathUUID.MatchString(part) || pathNumeric.MatchString(part) || pathHex.MatchString(part) {
+			parts[id] = "{id}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+// latency statistics of the requests to one endpoint
+type EndpointStats struct {
+	Method     string  `json:"Method"`
+	Path       string  `json:"Path"`
+	Calls      uint    `json:"Calls"`
+	Errors     uint    `json:"Errors"`
+	AvgLatency float64 `json:"AvgLatency"`
+	MaxLatency float64 `json:"MaxLatency"`
+}
+
+// the endpoints with the highest maximum latency
+func (r *Report) SlowestEndpoints(limit int) []EndpointStats {
+	stats := map[string]*EndpointStats{}
+	for _, d := range r.Details {
+		for _, c := range d.Calls {
+			key := c.Method + " " + c.Path
+			s, ok := stats[key]
+			if !ok {
+				s = &EndpointStats{Method: c.Method, Path: c.Path}
+				stats[key] = s
+			}
+			s.Calls++
+			s.AvgLatency += c.Latency
+			if c.Latency > s.MaxLatency {
+				s.MaxLatency = c.Latency
+			}
+			if c.IsError() {
+				s.Errors++
+			}
+		}
+	}
+
+	list := []EndpointStats{}
+	for _, s := range stats {
+		s.AvgLatency /= float64(s.Calls)
+		list = append(list, *s)
+	}
+	slices.SortFunc(list, func(a, b EndpointStats) int {
+		if a.MaxLatency > b.MaxLatency {
+			return -1
+		} else if a.MaxLatency < b.MaxLatency {
+			return 1
+		}
+		return strings.Compare(a.Method+a.Path, b.Method+b.Path)
+	})
+
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+	return list
+}
+
+type FailedAPICall struct {
+	Test ReportTestDetails
+	Call APICall
+}
+
+// every non-2xx response, in test order
+func (r *Report) FailedAPICalls() []FailedAPICall {
+	list := []FailedAPICall{}
+	for _, d := range r.Details {
+		for _, c := range d.Calls {
+			if c.IsError() {
+				list = append(list, FailedAPICall{Test: d, Call: c})
+			}
+		}
+	}
+	return list
+}
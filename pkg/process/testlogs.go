@@ -0,0 +1,161 @@
+package process
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// TestLogs writes the log lines of each thread to a separate file, and keeps the lines of each running test so that failures can be written to an excerpt
+type TestLogs struct {
+	dir     string
+	logger  *logrus.Logger
+	lock    sync.Mutex
+	threads map[int]*threadLog
+	closed  bool
+}
+
+type threadLog struct {
+	file    *os.File
+	excerpt *bytes.Buffer // nil when no test is running
+}
+
+// NewTestLogs creates the log directory and receives the log lines of the threads through a hook on the logger
+func NewTestLogs(dir string, logger *logrus.Logger) (*TestLogs, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	logs := &TestLogs{dir: dir, logger: logger, threads: map[int]*threadLog{}}
+	logger.AddHook(logs)
+	return logs, nil
+}
+
+func (l *TestLogs) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// lines logged through a types.ThreadLogger have the thread as a field
+func (l *TestLogs) Fire(entry *logrus.Entry) error {
+	thread, ok := entry.Data["thread"].(int)
+	if !ok {
+		return nil
+	}
+	line, err := entry.Logger.Formatter.Format(entry)
+	if err != nil {
+		return err
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.closed {
+		return nil
+	}
+
+	t, ok := l.threads[thread]
+	if !ok {
+		t = &threadLog{}
+		t.file, err = os.Create(filepath.Join(l.dir, fmt.Sprintf("thread_%d.log", thread)))
+		if err != nil {
+			t.file = nil
+			fmt.Fprintf(os.Stderr, "Failed to create log file for thread %d: %s\n", thread, err)
+		}
+		l.threads[thread] = t
+	}
+
+	if t.file != nil {
+		t.file.Write(line)
+	}
+	if t.excerpt != nil {
+		t.excerpt.Write(line)
+	}
+	return nil
+}
+
+// StartTest keeps the following lines of the thread, retries of a test continue the same excerpt
+func (l *TestLogs) StartTest(thread int) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	t, ok := l.threads[thread]
+	if !ok {
+		t = &threadLog{}
+		l.threads[thread] = t
+	}
+	if t.excerpt == nil {
+		t.excerpt = &bytes.Buffer{}
+	}
+}
+
+// FinishTest returns the lines of the thread since StartTest, if they should be kept
+func (l *TestLogs) FinishTest(thread int, keep bool) []byte {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	t, ok := l.threads[thread]
+	if !ok || t.excerpt == nil {
+		return nil
+	}
+	excerpt := t.excerpt
+	t.excerpt = nil
+	if !keep {
+		return nil
+	}
+	return excerpt.Bytes()
+}
+
+// Close removes the hook from the logger, which can outlive the run (eg: when the tests are run from Go code), and closes the thread logs
+func (l *TestLogs) Close() {
+	if l == nil {
+		return
+	}
+	hooks := logrus.LevelHooks{}
+	for level, levelHooks := range l.logger.Hooks {
+		for _, hook := range levelHooks {
+			if hook != logrus.Hook(l) {
+				hooks[level] = append(hooks[level], hook)
+			}
+		}
+	}
+	l.logger.ReplaceHooks(hooks)
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.closed = true
+	for _, t := range l.threads {
+		if t.file != nil {
+			t.file.Close()
+		}
+	}
+}
+
+// WriteLogExcerpts writes the log lines of each failed test to <log dir>/<test file name>.log and links them from the report
+func WriteLogExcerpts(reportData *Report, Config *TestConfig, logger *logrus.Logger) {
+	if Config.LogDir == "" {
+		return
+	}
+
+	for id := range reportData.Details {
+		d := &reportData.Details[id]
+		if len(d.log) == 0 {
+			continue
+		}
+
+		filename := filepath.Join(Config.LogDir, d.FileName()+".log")
+		if err := os.WriteFile(filename, d.log, 0600); err != nil {
+			logger.Errorf("Failed to write log excerpt for test %v: %s", d.Key, err)
+			continue
+		}
+		d.LogExcerpt = reportRelativePath(Config.ReportName, filename)
+	}
+}
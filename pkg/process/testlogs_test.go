@@ -0,0 +1,42 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+	"github.com/sirupsen/logrus"
+)
+
+func TestTestLogsFailSet(t *testing.T) {
+	logger := logrus.New()
+	logs, err := NewTestLogs(t.TempDir(), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	Config := &TestConfig{Logs: logs}
+
+	test := &types.ProjectCRUD{Name: "e2e-project"}
+	test.Test = "C"
+	thread := types.NewThreadLogger(logger, 1)
+	results := []TestResult{}
+	if err := RunTest(context.Background(), nil, &thread, types.OP_CREATE, "set", test, &results, Config, errors.New("previous test failed")); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Result != TST_FAIL {
+		t.Fatalf("expected a failed test, got %v", results)
+	}
+	if !strings.Contains(string(results[0].Log), "previous test failed") {
+		t.Errorf("log excerpt of a test failed by its set does not contain the reason: %q", results[0].Log)
+	}
+
+	logs.Close()
+	for level, hooks := range logger.Hooks {
+		if len(hooks) > 0 {
+			t.Errorf("logger still has %d %v hooks after closing the test logs", len(hooks), level)
+		}
+	}
+}
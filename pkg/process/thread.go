@@ -1,6 +1,7 @@
 package process
 
 import (
+	"context"
 	"sync"
 
 	"github.com/cxpsemea/Cx1ClientGo"
@@ -16,12 +17,19 @@ type TestDirector struct {
 	Config    *TestConfig
 	Lock      sync.Mutex
 	TestIndex int
+	Sessions  *types.AuditSessionManager // the audit sessions opened by the tests of this run
+	Context   context.Context            // the run, with its trace span, no further test sets are handed out once it is cancelled
 }
 
 func NewRunner(id int, dir *TestDirector, cx1client *Cx1ClientGo.Cx1Client, logger *logrus.Logger, Config *TestConfig, out chan<- *[]TestResult) {
 	tl := types.NewThreadLogger(logger, id)
+	tl.Sessions = dir.Sessions
+	if Config.ArtifactsDir != "" {
+		tl.Artifacts = types.NewArtifactCollector(artifactStagingDir(Config), id)
+	}
 
 	tl.Infof("Starting thread %d", id)
+	cx1client = threadClient(cx1client, &tl, Config)
 
 	all_results := []TestResult{}
 
@@ -31,10 +39,11 @@ func NewRunner(id int, dir *TestDirector, cx1client *Cx1ClientGo.Cx1Client, logg
 			break
 		}
 		logger.Infof("Thread %d picks up test set: %v [%v]", id, testSet.Name, testSet.TestSource)
+		Config.Events.Emit(Config, Event{Event: EVT_SET_STARTED, Thread: id, Set: testSet.Name, Source: testSet.TestSource})
 		client_clone := cx1client.Clone()
 		client_clone.SetLogger(tl)
 		testSet.SetActiveThread(id)
-		results := testSet.RunTests(&client_clone, &tl, Config, nil)
+		results := testSet.RunTests(dir.Context, &client_clone, &tl, Config, nil)
 		all_results = append(all_results, results...)
 	}
 
@@ -43,13 +52,33 @@ func NewRunner(id int, dir *TestDirector, cx1client *Cx1ClientGo.Cx1Client, logg
 	logger.Infof("Finished thread %d", id)
 }
 
+// the thread has its own client so that the requests it makes are attributed to the thread's tests
+func threadClient(cx1client *Cx1ClientGo.Cx1Client, logger *types.ThreadLogger, Config *TestConfig) *Cx1ClientGo.Cx1Client {
+	if Config.NewClient == nil {
+		return cx1client
+	}
+	httpClient, err := Config.CreateThreadHTTPClient(logger)
+	if err == nil {
+		var client *Cx1ClientGo.Cx1Client
+		if client, err = Config.NewClient(httpClient, logger.GetLogger()); err == nil {
+			client.SetDeprecationWarning(false)
+			return client
+		}
+	}
+	logger.Errorf("Failed to create the client of thread %d, its API calls will not be recorded: %s", logger.Thread, err)
+	return cx1client
+}
+
 func NewDirector(Config *TestConfig) TestDirector {
-	return TestDirector{Config: Config, TestIndex: 0}
+	return TestDirector{Config: Config, TestIndex: 0, Sessions: types.NewAuditSessionManager()}
 }
 
 func (d *TestDirector) GetNextTestSet() *TestSet {
 	d.Lock.Lock()
 	defer d.Lock.Unlock()
+	if d.Context != nil && d.Context.Err() != nil {
+		return nil
+	}
 	returnIndex := d.TestIndex
 	if returnIndex >= len(d.Config.Tests) {
 		return nil
@@ -0,0 +1,191 @@
+package process
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	coltracepb "go.opentelemetry.io/proto/otlp/collector/trace/v1"
+	tracepb "go.opentelemetry.io/proto/otlp/trace/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Tracer creates the OpenTelemetry spans of a run: run -> test set -> test -> HTTP request
+// the spans are passed down in contexts, Cx1ClientGo does not pass a context with its requests so the HTTP client
+// of each thread takes the context of the test running on the thread (see ThreadLogger.SetContext)
+type Tracer struct {
+	provider *sdktrace.TracerProvider
+	tracer   trace.Tracer
+	logger   *logrus.Logger
+}
+
+func NewTracer(logger *logrus.Logger, serviceVersion string, exporters ...sdktrace.SpanExporter) *Tracer {
+	options := []sdktrace.TracerProviderOption{
+		sdktrace.WithResource(resource.NewSchemaless(
+			attribute.String("service.name", "cx1e2e"),
+			attribute.String("service.version", serviceVersion),
+		)),
+	}
+	for _, exporter := range exporters {
+		options = append(options, sdktrace.WithBatcher(exporter))
+	}
+
+	provider := sdktrace.NewTracerProvider(options...)
+	return &Tracer{
+		provider: provider,
+		tracer:   provider.Tracer("github.com/cxpsemea/cx1e2e", trace.WithInstrumentationVersion(serviceVersion)),
+		logger:   logger,
+	}
+}
+
+// Start starts a span as a child of the span in the context, or a new trace if there is none
+func (t *Tracer) Start(ctx context.Context, name string, kind trace.SpanKind, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	if t == nil {
+		return ctx, trace.SpanFromContext(ctx)
+	}
+	return t.tracer.Start(ctx, name, trace.WithSpanKind(kind), trace.WithAttributes(attributes...))
+}
+
+// Flush exports the finished spans
+func (t *Tracer) Flush() {
+	if t == nil {
+		return
+	}
+	if err := t.provider.ForceFlush(context.Background()); err != nil {
+		t.logger.Errorf("Failed to export trace spans: %s", err)
+	}
+}
+
+// Shutdown exports the remaining spans and closes the exporters
+func (t *Tracer) Shutdown() {
+	if t == nil {
+		return
+	}
+	if err := t.provider.Shutdown(context.Background()); err != nil {
+		t.logger.Errorf("Failed to close trace exporters: %s", err)
+	}
+}
+
+// TraceID returns the trace ID of the span as shown in the reports, empty when tracing is disabled
+func TraceID(span trace.Span) string {
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		return sc.TraceID().String()
+	}
+	return ""
+}
+
+// Transport wraps the transport of a thread's http.Client so that each request made during a test is a span, and carries the traceparent header
+func (t *Tracer) Transport(base http.RoundTripper, thread *types.ThreadLogger) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &tracingTransport{base: base, tracer: t, thread: thread}
+}
+
+type tracingTransport struct {
+	base   http.RoundTripper
+	tracer *Tracer
+	thread *types.ThreadLogger
+}
+
+func (t *tracingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if t.thread != nil {
+		ctx = t.thread.GetContext(ctx)
+	}
+	// requests made outside of the run, eg: authentication when creating the client, are not part of a trace
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return t.base.RoundTrip(req)
+	}
+
+	path := TemplatePath(req.URL.Path)
+	url, _ := harURL(req.URL)
+	ctx, span := t.tracer.Start(ctx, fmt.Sprintf("%v %v", req.Method, path), trace.SpanKindClient,
+		attribute.String("http.request.method", req.Method),
+		attribute.String("url.full", url),
+		attribute.String("url.template", path),
+		attribute.String("server.address", req.URL.Hostname()),
+	)
+	defer span.End()
+
+	// a RoundTripper should not modify the request it was given
+	req = req.Clone(ctx)
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		span.SetAttributes(attribute.String("error.type", fmt.Sprintf("%T", err)))
+		span.SetStatus(codes.Error, err.Error())
+		return resp, err
+	}
+
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetAttributes(attribute.String("error.type", strconv.Itoa(resp.StatusCode)))
+		span.SetStatus(codes.Error, "")
+	}
+	return resp, nil
+}
+
+// writes each batch of spans as a line of OTLP/JSON, the format read by the OpenTelemetry collector's otlpjsonfile receiver
+type fileTraceClient struct {
+	lock   sync.Mutex
+	writer io.Writer
+	file   *os.File
+}
+
+func (c *fileTraceClient) Start(ctx context.Context) error {
+	return nil
+}
+
+func (c *fileTraceClient) Stop(ctx context.Context) error {
+	if c.file != nil {
+		return c.file.Close()
+	}
+	return nil
+}
+
+func (c *fileTraceClient) UploadTraces(ctx context.Context, spans []*tracepb.ResourceSpans) error {
+	data, err := protojson.Marshal(&coltracepb.ExportTraceServiceRequest{ResourceSpans: spans})
+	if err != nil {
+		return err
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, err = c.writer.Write(append(data, '\n'))
+	return err
+}
+
+// NewFileSpanExporter writes the spans to a file, or to stdout when the filename is "-"
+func NewFileSpanExporter(filename string) (sdktrace.SpanExporter, error) {
+	client := &fileTraceClient{writer: os.Stdout}
+	if filename != "-" {
+		file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, err
+		}
+		client.writer = file
+		client.file = file
+	}
+	return otlptrace.New(context.Background(), client)
+}
+
+// NewHTTPSpanExporter sends the spans to an OTLP/HTTP endpoint, eg: http://localhost:4318/v1/traces
+// it uses its own client, the requests sending the spans should not be traced themselves
+func NewHTTPSpanExporter(endpoint string) (sdktrace.SpanExporter, error) {
+	return otlptracehttp.New(context.Background(), otlptracehttp.WithEndpointURL(endpoint))
+}
@@ -0,0 +1,91 @@
+package process
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cxpsemea/cx1e2e/pkg/types"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracerThreadTransport(t *testing.T) {
+	var traceparent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceparent = r.Header.Get("traceparent")
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "traces.jsonl")
+	exporter, err := NewFileSpanExporter(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := NewTracer(logrus.New(), "test", exporter)
+
+	logger := types.NewThreadLogger(logrus.New(), 1)
+	client := &http.Client{Transport: tracer.Transport(nil, &logger)}
+
+	// outside of a test, requests are not traced
+	if _, err := client.Get(server.URL + "/api/projects"); err != nil {
+		t.Fatal(err)
+	}
+	if traceparent != "" {
+		t.Errorf("request outside of a test has traceparent %v", traceparent)
+	}
+
+	ctx, span := tracer.Start(context.Background(), "Create Project", trace.SpanKindInternal)
+	logger.SetContext(ctx)
+	if _, err := client.Get(server.URL + "/api/projects/0d4c4f0a-8f5e-4c2a-9c0b-1f2e3d4c5b6a"); err != nil {
+		t.Fatal(err)
+	}
+	logger.SetContext(nil)
+	span.End()
+
+	traceID := TraceID(span)
+	if !strings.Contains(traceparent, traceID) {
+		t.Errorf("traceparent %v does not contain the trace ID %v of the test", traceparent, traceID)
+	}
+	tracer.Shutdown()
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var request struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID      string `json:"traceId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Name         string `json:"name"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	if err := json.Unmarshal([]byte(strings.Split(string(data), "\n")[0]), &request); err != nil {
+		t.Fatalf("trace file is not OTLP/JSON: %s", err)
+	}
+
+	names := []string{}
+	for _, rs := range request.ResourceSpans {
+		for _, ss := range rs.ScopeSpans {
+			for _, s := range ss.Spans {
+				names = append(names, s.Name)
+				if s.Name == "GET /api/projects/{id}" && s.ParentSpanID == "" {
+					t.Errorf("request span has no parent")
+				}
+			}
+		}
+	}
+	if len(names) != 2 {
+		t.Errorf("expected the test and request spans, got %v", names)
+	}
+}
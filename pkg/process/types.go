@@ -1,6 +1,8 @@
 package process
 
 import (
+	"io"
+
 	"github.com/cxpsemea/Cx1ClientGo"
 	"github.com/cxpsemea/cx1e2e/pkg/types"
 )
@@ -14,25 +16,10 @@ type TestSet struct {
 		ClientSecret string `yaml:"ClientSecret"`
 	} `yaml:"RunAs"`
 
-	AccessAssignments []types.AccessAssignmentCRUD `yaml:"AccessAssignments"`
-	Analytics         []types.AnalyticsCRUD        `yaml:"Analytics"`
-	Applications      []types.ApplicationCRUD      `yaml:"Applications"`
-	Branches          []types.BranchCRUD           `yaml:"Branches"`
-	Clients           []types.OIDCClientCRUD       `yaml:"OIDCClients"`
-	Flags             []types.FlagCRUD             `yaml:"Flags"`
-	Groups            []types.GroupCRUD            `yaml:"Groups"`
-	Imports           []types.ImportCRUD           `yaml:"Imports"`
-	Presets           []types.PresetCRUD           `yaml:"Presets"`
-	Projects          []types.ProjectCRUD          `yaml:"Projects"`
-	Queries           []types.CxQLCRUD             `yaml:"Queries"`
-	Reports           []types.ReportCRUD           `yaml:"Reports"`
-	Results           []types.ResultCRUD           `yaml:"Results"`
-	Roles             []types.RoleCRUD             `yaml:"Roles"`
-	Scans             []types.ScanCRUD             `yaml:"Scans"`
-	Users             []types.UserCRUD             `yaml:"Users"`
-	Wait              uint                         `yaml:"Wait"`
-	Thread            uint                         `yaml:"Thread"`
-	ActiveThread      int                          `yaml:"-"`
+	Modules      map[string][]TestRunner `yaml:"-"` // the tests of each registered module, keyed by module name
+	Wait         uint                    `yaml:"Wait"`
+	Thread       uint                    `yaml:"Thread"`
+	ActiveThread int                     `yaml:"-"`
 
 	SubTests   []TestSet `yaml:"-"`
 	TestSource string    `yaml:"-"`
@@ -53,11 +40,62 @@ type TestConfig struct {
 	AuthUser           string                  `yaml:"-"`
 	ReportType         string                  `yaml:"ReportType"`
 	ReportName         string                  `yaml:"ReportName"`
+	HistoryDir         string                  `yaml:"HistoryDir"`
 	Engines            types.EnabledEngines    `yaml:"-"`
 	EnvironmentVersion Cx1ClientGo.VersionInfo `yaml:"-"`
 	TestCount          int                     `yaml:"-"`
 	IPv4               bool                    `yaml:"-"`
 	IPv6               bool                    `yaml:"-"`
+	PreExisting        PreExistingObjects      `yaml:"PreExisting"`
+	Environments       []Environment           `yaml:"Environments"`
+	Environment        string                  `yaml:"-"` // name of the environment currently targeted, if any
+	Notifications      []Notification          `yaml:"Notifications"`
+	DurationBudgets    []ModuleBudget          `yaml:"DurationBudgets"`
+	Notifier           *Notifier               `yaml:"-"`
+	Telemetry          *APITelemetry           `yaml:"-"`
+	NewClient          ClientFactory           `yaml:"-"` // creates the client of each thread, the shared client is cloned if nil
+	Cassette           *Cassette               `yaml:"-"` // record the HTTP interactions, or replay them without network access
+	HAR                string                  `yaml:"-"` // capture HTTP traffic of failing tests, or all tests, as HAR files
+	ArtifactsDir       string                  `yaml:"-"` // keep the files downloaded by the tests
+	LogDir             string                  `yaml:"-"` // write a log per thread and per failed test
+	Logs               *TestLogs               `yaml:"-"`
+	Metrics            *Metrics                `yaml:"-"`
+	MetricsFile        string                  `yaml:"-"`
+	LegacySummary      bool                    `yaml:"-"`
+	Console            io.Writer               `yaml:"-"` // where the summary, comparison and inline reports are printed, stdout if nil
+	Tracer             *Tracer                 `yaml:"-"`
+	Events             *EventWriter            `yaml:"-"`
+	Hooks              *RunHooks               `yaml:"-"` // callbacks of a Runner embedding cx1e2e
+}
+
+// a named Cx1 environment that the same test suite can be run against
+// credentials are referenced by the name of the environment variable holding them, so they are not stored in the config
+type Environment struct {
+	Name            string `yaml:"Name"`
+	Cx1URL          string `yaml:"Cx1URL"`
+	IAMURL          string `yaml:"IAMURL"`
+	Tenant          string `yaml:"Tenant"`
+	APIKeyEnv       string `yaml:"APIKeyEnv"`
+	ClientIDEnv     string `yaml:"ClientIDEnv"`
+	ClientSecretEnv string `yaml:"ClientSecretEnv"`
+	AccessTokenEnv  string `yaml:"AccessTokenEnv"`
+}
+
+// objects which exist in the tenant before the test run, referenced by tests but not created by them
+type PreExistingObjects struct {
+	Applications []string `yaml:"Applications"`
+	Clients      []string `yaml:"OIDCClients"`
+	Groups       []string `yaml:"Groups"`
+	Presets      []string `yaml:"Presets"`
+	Projects     []string `yaml:"Projects"`
+	Roles        []string `yaml:"Roles"`
+	Users        []string `yaml:"Users"`
+}
+
+// default duration budget for the tests of a module, eg: all scans, or creating projects
+type ModuleBudget struct {
+	Module           string `yaml:"Module"`
+	types.TestBudget `yaml:",inline"`
 }
 
 type TestResult struct {
@@ -72,13 +110,22 @@ type TestResult struct {
 	Reason     []string
 	TestSource string
 	Attempts   uint
+	Thread     int
+	Calls      []APICall
+	TraceID    string
+	Labels     []string
+	Budget     *types.DurationBudget
+	Artifacts  []types.Artifact
+	Log        []byte // the log lines of a failed test, with --log-dir
 }
 
 // test result output
 type Counter struct {
-	Pass uint
-	Fail uint
-	Skip uint
+	Pass  uint
+	Fail  uint
+	Skip  uint
+	Slow  uint `json:"Slow,omitempty"`  // passed, but took longer than the MaxDuration budget
+	Flaky uint `json:"Flaky,omitempty"` // passed only after being retried, these are also counted as Pass
 }
 
 type CounterSet struct {
@@ -89,52 +136,54 @@ type CounterSet struct {
 }
 
 type ReportSettings struct {
-	Target    string                  `json:"TestTarget"`
-	Auth      string                  `json:"Authentication"`
-	Config    string                  `json:"TestConfig"`
-	StartTime string                  `json:"StartTime"`
-	EndTime   string                  `json:"EndTime"`
-	Duration  string                  `json:"Duration"`
-	E2ESuffix string                  `json:"E2ESuffix"`
-	Threads   int                     `json:"Threads"`
-	Version   Cx1ClientGo.VersionInfo `json:"TargetVersions"`
+	Target      string                  `json:"TestTarget"`
+	Environment string                  `json:"Environment,omitempty"`
+	Auth        string                  `json:"Authentication"`
+	Config      string                  `json:"TestConfig"`
+	StartTime   string                  `json:"StartTime"`
+	EndTime     string                  `json:"EndTime"`
+	Duration    string                  `json:"Duration"`
+	E2ESuffix   string                  `json:"E2ESuffix"`
+	Threads     int                     `json:"Threads"`
+	Version     Cx1ClientGo.VersionInfo `json:"TargetVersions"`
 }
 
 type ReportSummary struct {
-	Total Counter `json:"Total"`
-	Area  struct {
-		Access      CounterSet
-		Application CounterSet
-		Analytics   CounterSet
-		Branch      CounterSet
-		Client      CounterSet
-		Flag        CounterSet
-		Group       CounterSet
-		Import      CounterSet
-		Preset      CounterSet
-		Project     CounterSet
-		Query       CounterSet
-		Result      CounterSet
-		Report      CounterSet
-		Role        CounterSet
-		Scan        CounterSet
-		User        CounterSet
-	} `json:"Area"`
+	Total   Counter                `json:"Total"`
+	Modules map[string]*CounterSet `json:"Modules"` // keyed by module name, eg: Project
+	Legacy  bool                   `json:"-"`       // write the fixed Area structure of earlier versions instead of Modules
 }
 
 type ReportTestDetails struct {
+	Key         string `json:"Key"` // identifies the test across runs, unlike the ID which changes when tests are added to the configuration
 	Name        string
 	Source      string
 	Test        string
+	Module      string `json:"Module,omitempty"`
+	CRUD        string `json:"CRUD,omitempty"`
+	Object      string `json:"Object,omitempty"`
+	Negative    bool   `json:"Negative,omitempty"`
+	Attempts    uint   `json:"Attempts,omitempty"` // when the test was retried
 	Duration    float64
 	ResultType  int `json:"-"`
 	Result      string
 	ID          uint
-	FailOutputs []string `json:"FailOutputs,omitempty"`
+	FailOutputs []string         `json:"FailOutputs,omitempty"`
+	Thread      int              `json:"Thread,omitempty"`
+	LogExcerpt  string           `json:"LogExcerpt,omitempty"` // path to the log lines of this test, relative to the report
+	Calls       []APICall        `json:"Calls,omitempty"`
+	HAR         string           `json:"HAR,omitempty"`       // path to the captured HTTP traffic of this test, relative to the report
+	Artifacts   []types.Artifact `json:"Artifacts,omitempty"` // files downloaded by this test, with paths relative to the report
+	TraceID     string           `json:"TraceID,omitempty"`
+
+	Budget *types.DurationBudget `json:"Budget,omitempty"`
+
+	log []byte // written to the LogExcerpt file
 }
 
 type Report struct {
 	Settings ReportSettings      `json:"Settings"`
 	Summary  ReportSummary       `json:"Summary"`
 	Details  []ReportTestDetails `json:"Details"`
+	SLA      []SLAEntry          `json:"SLA,omitempty"`
 }
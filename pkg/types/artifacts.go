@@ -0,0 +1,62 @@
+package types
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+)
+
+var unsafeArtifactChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// a file downloaded by a test, eg: a generated report or the scan logs
+type Artifact struct {
+	Name string `json:"Name"`
+	Path string `json:"Path"`
+}
+
+// ArtifactCollector saves the files downloaded by the tests of a thread until the report links them to the test
+type ArtifactCollector struct {
+	dir       string
+	lock      sync.Mutex
+	count     int
+	artifacts []Artifact
+}
+
+// NewArtifactCollector stores the files of a thread in dir, which is created when the first file is saved
+func NewArtifactCollector(dir string, thread int) *ArtifactCollector {
+	return &ArtifactCollector{dir: filepath.Join(dir, fmt.Sprintf("T%d", thread))}
+}
+
+func (c *ArtifactCollector) Save(name string, data []byte) error {
+	if c == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	c.count++
+	name = unsafeArtifactChars.ReplaceAllString(name, "_")
+	path := filepath.Join(c.dir, fmt.Sprintf("%d_%v", c.count, name))
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return err
+	}
+	c.artifacts = append(c.artifacts, Artifact{Name: name, Path: path})
+	return nil
+}
+
+// Take returns the files saved since the last call, ie: by the test which just finished
+func (c *ArtifactCollector) Take() []Artifact {
+	if c == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	artifacts := c.artifacts
+	c.artifacts = nil
+	return artifacts
+}
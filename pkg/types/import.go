@@ -64,11 +64,14 @@ func (t *ImportCRUD) RunCreate(cx1client *Cx1ClientGo.Cx1Client, logger *ThreadL
 		return fmt.Errorf("failed during import: %s", err)
 	}
 
+	logs, err := cx1client.GetImportLogsByID(importID)
+	if err == nil {
+		logger.SaveArtifact("import.log", logs)
+	}
+
 	if result == "Failed" {
 		return fmt.Errorf("import failed")
 	}
-
-	logs, err := cx1client.GetImportLogsByID(importID)
 	if err != nil {
 		return err
 	}
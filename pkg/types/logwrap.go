@@ -1,7 +1,9 @@
 package types
 
 import (
+	"context"
 	"fmt"
+	"sync/atomic"
 
 	"github.com/sirupsen/logrus"
 )
@@ -11,8 +13,21 @@ type LeveledLogger struct {
 }
 
 type ThreadLogger struct {
-	logger *logrus.Logger
-	Thread int
+	logger    *logrus.Logger
+	Thread    int
+	Artifacts *ArtifactCollector   // nil unless the downloaded files are kept with --artifacts-dir
+	Sessions  *AuditSessionManager // the audit sessions of the run, shared by its threads
+	test      *atomic.Pointer[TestContext]
+	ctx       *atomic.Pointer[context.Context]
+}
+
+// the test which is running on a thread
+type TestContext struct {
+	ID     uint
+	Set    string
+	Module string
+	CRUD   string
+	Object string
 }
 
 func (l LeveledLogger) Error(msg string, keysAndValues ...interface{}) {
@@ -50,62 +65,130 @@ func NewLeveledLogger(logger *logrus.Logger) LeveledLogger {
 }
 
 func (l ThreadLogger) Errorf(msg string, keysAndValues ...interface{}) {
-	l.logger.Error(l.makeMsgf(msg, keysAndValues...))
+	l.log(logrus.ErrorLevel, l.makeMsgf(msg, keysAndValues...))
 }
 func (l ThreadLogger) Error(msg string) {
-	l.logger.Error(l.makeMsg(msg))
+	l.log(logrus.ErrorLevel, l.makeMsg(msg))
 }
 
 func (l ThreadLogger) Fatalf(msg string, keysAndValues ...interface{}) {
-	l.logger.Fatal(l.makeMsgf(msg, keysAndValues...))
+	l.entry().Fatal(l.makeMsgf(msg, keysAndValues...))
 }
 func (l ThreadLogger) Fatal(msg string) {
-	l.logger.Fatal(l.makeMsg(msg))
+	l.entry().Fatal(l.makeMsg(msg))
 }
 
 func (l ThreadLogger) Infof(msg string, keysAndValues ...interface{}) {
-	l.logger.Info(l.makeMsgf(msg, keysAndValues...))
+	l.log(logrus.InfoLevel, l.makeMsgf(msg, keysAndValues...))
 }
 func (l ThreadLogger) Info(msg string) {
-	l.logger.Info(l.makeMsg(msg))
+	l.log(logrus.InfoLevel, l.makeMsg(msg))
 }
 
 func (l ThreadLogger) Debugf(msg string, keysAndValues ...interface{}) {
-	l.logger.Debug(l.makeMsgf(msg, keysAndValues...))
+	l.log(logrus.DebugLevel, l.makeMsgf(msg, keysAndValues...))
 }
 func (l ThreadLogger) Debug(msg string) {
-	l.logger.Debug(l.makeMsg(msg))
+	l.log(logrus.DebugLevel, l.makeMsg(msg))
 }
 
 func (l ThreadLogger) Tracef(msg string, keysAndValues ...interface{}) {
-	l.logger.Trace(l.makeMsgf(msg, keysAndValues...))
+	l.log(logrus.TraceLevel, l.makeMsgf(msg, keysAndValues...))
 }
 func (l ThreadLogger) Trace(msg string) {
-	l.logger.Trace(l.makeMsg(msg))
+	l.log(logrus.TraceLevel, l.makeMsg(msg))
 }
 
 func (l ThreadLogger) Warnf(msg string, keysAndValues ...interface{}) {
-	l.logger.Warn(l.makeMsgf(msg, keysAndValues...))
+	l.log(logrus.WarnLevel, l.makeMsgf(msg, keysAndValues...))
 }
 func (l ThreadLogger) Warn(msg string) {
-	l.logger.Warn(l.makeMsg(msg))
+	l.log(logrus.WarnLevel, l.makeMsg(msg))
 }
 
 func (l ThreadLogger) Logger() *logrus.Logger {
 	return l.logger
 }
+
+func (l ThreadLogger) log(level logrus.Level, msg string) {
+	if l.logger.IsLevelEnabled(level) {
+		l.entry().Log(level, msg)
+	}
+}
+
+// the thread and the test it is running as fields, for the JSON log format
+func (l ThreadLogger) entry() *logrus.Entry {
+	fields := logrus.Fields{"thread": l.Thread}
+	if test := l.GetTest(); test != nil {
+		fields["test_id"] = test.ID
+		fields["set"] = test.Set
+		fields["module"] = test.Module
+		fields["crud"] = test.CRUD
+		fields["object"] = test.Object
+	}
+	return l.logger.WithFields(fields)
+}
+
+// the text format has the thread as a prefix instead
+func (l ThreadLogger) structured() bool {
+	_, ok := l.logger.Formatter.(*logrus.JSONFormatter)
+	return ok
+}
+
 func (l ThreadLogger) makeMsgf(msg string, keysAndValues ...interface{}) string {
-	origMsg := fmt.Sprintf(msg, keysAndValues...)
-	return fmt.Sprintf("[T%d] %v", l.Thread, origMsg)
+	return l.makeMsg(fmt.Sprintf(msg, keysAndValues...))
 }
 func (l ThreadLogger) makeMsg(msg string) string {
+	if l.structured() {
+		return msg
+	}
 	return fmt.Sprintf("[T%d] %v", l.Thread, msg)
 }
 
+// SetTest sets the test which the thread is running, which is also seen by the copies of the logger (eg: the one used by the Cx1 client)
+func (l ThreadLogger) SetTest(test *TestContext) {
+	if l.test != nil {
+		l.test.Store(test)
+	}
+}
+
+func (l ThreadLogger) GetTest() *TestContext {
+	if l.test == nil {
+		return nil
+	}
+	return l.test.Load()
+}
+
+// SetContext sets the context of the test set or test which the thread is running, eg: its trace span,
+// which is used by the HTTP client of the thread since Cx1ClientGo does not pass a context with its requests
+func (l ThreadLogger) SetContext(ctx context.Context) {
+	if l.ctx != nil {
+		l.ctx.Store(&ctx)
+	}
+}
+
+// GetContext returns the context set with SetContext, or fallback if there is none
+func (l ThreadLogger) GetContext(fallback context.Context) context.Context {
+	if l.ctx == nil {
+		return fallback
+	}
+	if ctx := l.ctx.Load(); ctx != nil && *ctx != nil {
+		return *ctx
+	}
+	return fallback
+}
+
 func (l ThreadLogger) GetLogger() *logrus.Logger {
 	return l.logger
 }
 
+// SaveArtifact keeps a file downloaded by the running test, a failure to save it does not fail the test
+func (l ThreadLogger) SaveArtifact(name string, data []byte) {
+	if err := l.Artifacts.Save(name, data); err != nil {
+		l.Warnf("Failed to save artifact %v: %s", name, err)
+	}
+}
+
 func NewThreadLogger(logger *logrus.Logger, thread int) ThreadLogger {
-	return ThreadLogger{logger: logger, Thread: thread}
+	return ThreadLogger{logger: logger, Thread: thread, test: &atomic.Pointer[TestContext]{}, ctx: &atomic.Pointer[context.Context]{}}
 }
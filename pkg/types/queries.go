@@ -9,13 +9,6 @@ import (
 	"github.com/cxpsemea/Cx1ClientGo"
 )
 
-// var auditSession *Cx1ClientGo.AuditSession
-var ASM *AuditSessionManager
-
-func init() {
-	ASM = NewAuditSessionManager()
-}
-
 func (t *CxQLCRUD) Validate(CRUD string) error {
 	if t.QueryGroup == "" || t.QueryName == "" || (t.QueryLanguage == "" && t.QueryPlatform == "") {
 		return fmt.Errorf("query language|platform, group, or name is missing")
@@ -77,7 +70,10 @@ func getAuditSession(cx1client *Cx1ClientGo.Cx1Client, logger *ThreadLogger, t *
 		t.LastScan = &lastscans[0]
 	}
 
-	return ASM.GetOrCreateSession(t.ActiveThread, t.Scope, t.Engine, t.QueryPlatform, t.QueryLanguage, t.LastScan, cx1client, logger)
+	if logger.Sessions == nil {
+		return nil, fmt.Errorf("unable to create audit session: no audit session manager for this run")
+	}
+	return logger.Sessions.GetOrCreateSession(t.ActiveThread, t.Scope, t.Engine, t.QueryPlatform, t.QueryLanguage, t.LastScan, cx1client, logger)
 
 }
 
@@ -418,15 +414,15 @@ func (t *CxQLCRUD) TerminateSession(session_source string, cx1client *Cx1ClientG
 	// a session can be created by the automatic Read operation inserted prior to an Update or Delete operation.
 	// in that case, the session would be created & deleted during the RunRead part, and no longer exist when the Update/Delete executes
 	// so we only want to terminate the session if it was created during the same operation as the test
-	if t.DeleteSession && t.CRUDTest.IsType(session_source) && t.LastScan != nil {
-		auditSession, err := ASM.GetSession(t.ActiveThread, t.Scope, t.Engine, t.QueryPlatform, t.QueryLanguage, t.LastScan, cx1client, logger)
+	if t.DeleteSession && t.CRUDTest.IsType(session_source) && t.LastScan != nil && logger.Sessions != nil {
+		auditSession, err := logger.Sessions.GetSession(t.ActiveThread, t.Scope, t.Engine, t.QueryPlatform, t.QueryLanguage, t.LastScan, cx1client, logger)
 		if err != nil {
 			logger.Errorf("Failed to get audit session: %s", err)
 			return
 		}
 
 		if auditSession != nil {
-			err = ASM.DeleteSession(auditSession, cx1client, logger)
+			err = logger.Sessions.DeleteSession(auditSession, cx1client, logger)
 			if err != nil {
 				logger.Errorf("Failed to delete Audit session %v: %s", auditSession.ID, err)
 			}
@@ -679,6 +675,7 @@ func (t *CxQLCRUD) RunRead(cx1client *Cx1ClientGo.Cx1Client, logger *ThreadLogge
 		}
 
 		t.SASTQuery = query
+		logger.SaveArtifact(t.QueryName+".cs", []byte(query.Source))
 	} else if t.Engine == "iac" {
 		var query *Cx1ClientGo.IACQuery
 		query, _ = getIACQuery(cx1client, logger, t)
@@ -704,6 +701,7 @@ func (t *CxQLCRUD) RunRead(cx1client *Cx1ClientGo.Cx1Client, logger *ThreadLogge
 		}
 
 		t.IACQuery = query
+		logger.SaveArtifact(t.QueryName+".rego", []byte(query.Source))
 	}
 
 	return nil
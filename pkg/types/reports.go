@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/cxpsemea/Cx1ClientGo"
 )
@@ -146,10 +147,11 @@ func (t *ReportCRUD) RunCreate(cx1client *Cx1ClientGo.Cx1Client, logger *ThreadL
 		return err
 	}
 
-	_, err = cx1client.DownloadReport(reportURL)
+	report, err := cx1client.DownloadReport(reportURL)
 	if err != nil {
 		return err
 	}
+	logger.SaveArtifact("report."+strings.ToLower(t.Format), report)
 
 	return nil
 }
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -72,6 +73,7 @@ func (t *ScanCRUD) GetLogs(cx1client *Cx1ClientGo.Cx1Client, logger *ThreadLogge
 					if len(bytes) == 0 {
 						return fmt.Errorf("%v scan logs had no data", eng)
 					}
+					logger.SaveArtifact(eng+".log", bytes)
 				}
 			}
 		}
@@ -240,6 +242,9 @@ func (t *ScanCRUD) RunCreate(cx1client *Cx1ClientGo.Cx1Client, logger *ThreadLog
 				logger.Errorf("Failed to get workflow update for scan %v: %s", test_Scan.ScanID, err)
 				return fmt.Errorf("scan finished with status '%v' (%v) but %v was expected", test_Scan.Status, fail_reason, expectedResult)
 			} else {
+				if data, err := json.MarshalIndent(workflow, "", "  "); err == nil {
+					logger.SaveArtifact("workflow.json", data)
+				}
 				if len(workflow) == 0 {
 					return fmt.Errorf("scan finished with status '%v' (%v) but %v was expected, there was no workflow log available for additional details", test_Scan.Status, fail_reason, expectedResult)
 				} else {
@@ -9,6 +9,25 @@ func (c CRUDTest) IsNegative() bool {
 	return c.FailTest
 }
 
+// SetType changes which CRUD operations the test runs, eg: to only delete the objects created by the test during cleanup
+func (c *CRUDTest) SetType(CRUD string) {
+	c.Test = CRUD
+}
+
+// SetSource sets the file which defines the test, and the thread it is pinned to (0 for any)
+func (c *CRUDTest) SetSource(source string, thread uint) {
+	c.TestSource = source
+	c.Thread = thread
+}
+
+func (c *CRUDTest) SetID(id uint) {
+	c.TestID = id
+}
+
+func (c *CRUDTest) SetActiveThread(thread int) {
+	c.ActiveThread = thread
+}
+
 func (c CRUDTest) IsType(CRUD string) bool {
 	switch CRUD {
 	case OP_CREATE:
@@ -65,6 +84,54 @@ func (c CRUDTest) GetCurrentThread() int {
 	return c.ActiveThread
 }
 
+func (c CRUDTest) GetLabels() []string {
+	return c.Labels
+}
+
+// GetBudget returns the duration budget of the test for this CRUD operation
+// a budget for specific operations (eg: Test: C) takes precedence over one without
+func (c CRUDTest) GetBudget(CRUD string) DurationBudget {
+	var budget DurationBudget
+	for _, b := range c.Budgets {
+		if b.Test == "" {
+			if !budget.IsSet() {
+				budget = b.DurationBudget
+			}
+		} else if strings.Contains(b.Test, CRUD[:1]) {
+			return b.DurationBudget
+		}
+	}
+	return budget
+}
+
+func (b DurationBudget) IsSet() bool {
+	return b.MaxDuration > 0 || b.WarnDuration > 0
+}
+
+// the status of a duration against the budget: OK, WARN (over WarnDuration) or SLOW (over MaxDuration)
+func (b DurationBudget) Check(duration float64) string {
+	if b.MaxDuration > 0 && duration > b.MaxDuration {
+		return "SLOW"
+	}
+	if b.WarnDuration > 0 && duration > b.WarnDuration {
+		return "WARN"
+	}
+	return "OK"
+}
+
+func (b DurationBudget) Validate() error {
+	if b.MaxDuration < 0 || b.WarnDuration < 0 {
+		return fmt.Errorf("durations must not be negative")
+	}
+	if b.MaxDuration > 0 && b.WarnDuration > b.MaxDuration {
+		return fmt.Errorf("WarnDuration %v is longer than MaxDuration %v", b.WarnDuration, b.MaxDuration)
+	}
+	if b.StrictDuration && b.MaxDuration == 0 {
+		return fmt.Errorf("StrictDuration requires a MaxDuration")
+	}
+	return nil
+}
+
 func (c CRUDTest) Validate(CRUD string) error {
 	return fmt.Errorf("not implemented")
 }
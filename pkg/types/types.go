@@ -75,7 +75,22 @@ type CRUDTest struct {
 	OnFailAction FailAction     `yaml:"OnFail"`   // actions to take if this command fails
 	TestID       uint           `yaml:"-"`        // internal ID for the test
 	Thread       uint           `yaml:"Thread"`
-	ActiveThread int            `yaml:"-"` // when a runner picks up a test, the test is updated with the owning thread
+	ActiveThread int            `yaml:"-"`               // when a runner picks up a test, the test is updated with the owning thread
+	Labels       []string       `yaml:"Labels"`          // free-form labels, eg: to send a notification when a "critical" test fails
+	Budgets      []TestBudget   `yaml:"DurationBudgets"` // duration budgets of the CRUD operations of this test
+}
+
+// performance budget of a test operation, in seconds
+type DurationBudget struct {
+	MaxDuration    float64 `yaml:"MaxDuration" json:"MaxDuration,omitempty"`       // a passing test which takes longer is SLOW
+	WarnDuration   float64 `yaml:"WarnDuration" json:"WarnDuration,omitempty"`     // a passing test which takes longer is only reported in the SLA section
+	StrictDuration bool    `yaml:"StrictDuration" json:"StrictDuration,omitempty"` // a test which takes longer than MaxDuration fails
+}
+
+// duration budget of some CRUD operations
+type TestBudget struct {
+	Test           string `yaml:"Test"` // the CRUD operations which the budget applies to, all if empty
+	DurationBudget `yaml:",inline"`
 }
 
 type FailAction struct {